@@ -15,9 +15,35 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/monitoring/cache": {
+            "get": {
+                "description": "Get the hits, misses and upstream calls of the cache of TheSportsDB lookups",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Get the third party cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CacheStatsDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
         "/players": {
             "get": {
-                "description": "Get a list of all players",
+                "description": "Get a page of players, filtered and sorted",
                 "produces": [
                     "application/json"
                 ],
@@ -25,6 +51,50 @@ const docTemplate = `{
                     "players"
                 ],
                 "summary": "Get all players",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the name of the player, ignoring the case and the diacritics",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last name, ignoring the case and the diacritics",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of a team the players are on the roster of",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields among id, first_name and last_name, descending when prefixed by -",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of players to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of players, 50 by default and at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, from the Link header",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -33,6 +103,34 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/dto.PlayerDTO"
                             }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the first, previous, next and last pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Number of players matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
@@ -66,6 +164,88 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.PlayerDTO"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/duplicates": {
+            "get": {
+                "description": "Get the clusters of players suspected to be the same person, scored on the similarity of their names, their dates of birth and their IDs at the providers, highest score first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get the suspected duplicate players",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Score from 0 to 1 from which two players are suspected duplicates, 0.8 by default",
+                        "name": "min_score",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of a team a player of the clusters is on the roster of",
+                        "name": "team_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DuplicateClustersDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
                     }
                 }
             }
@@ -94,6 +274,39 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/dto.PlayerDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Player version"
+                            }
+                        }
+                    },
+                    "301": {
+                        "description": "Moved Permanently, to the player a merged duplicate was merged into",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the player the duplicate was merged into"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
@@ -118,6 +331,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Expected player version",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
                     {
                         "description": "Player data",
                         "name": "player",
@@ -133,6 +352,48 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/dto.PlayerDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Player version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
@@ -150,39 +411,113 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected player version",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
                     }
                 }
             }
         },
-        "/teams": {
+        "/players/{id}/history": {
             "get": {
-                "description": "Get a list of all teams",
+                "description": "Get the changes of a player, most recent first, with the actor, the source and the field-level differences",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teams"
+                    "players"
+                ],
+                "summary": "Get the history of a player",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries, 50 by default and at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all teams",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.TeamDTO"
-                            }
+                            "$ref": "#/definitions/dto.HistoryPageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/players/{id}/merge": {
             "post": {
-                "description": "Create a new team with the provided details",
+                "description": "Merge duplicates into a player, which keeps its fields and takes the ones it misses and the IDs at the providers from the duplicates. The duplicates are replaced by the player on the team rosters and deleted, their IDs redirecting to the player.",
                 "consumes": [
                     "application/json"
                 ],
@@ -190,44 +525,99 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "teams"
+                    "players"
                 ],
-                "summary": "Create a new team",
+                "summary": "Merge duplicates into a player",
                 "parameters": [
                     {
-                        "description": "Team data",
-                        "name": "team",
+                        "type": "integer",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected player version",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Duplicates to merge",
+                        "name": "merge",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamDTO"
+                            "$ref": "#/definitions/dto.PlayerMergeDTO"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamDTO"
+                            "$ref": "#/definitions/dto.PlayerDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Player version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
             }
         },
-        "/teams/{id}": {
-            "get": {
-                "description": "Get a team by its ID",
+        "/players/{id}/restore": {
+            "post": {
+                "description": "Restore a player from the trash, without adding it back to the team rosters",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teams"
+                    "players"
                 ],
-                "summary": "Get a team by ID",
+                "summary": "Restore a deleted player",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Team ID",
+                        "description": "Player ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -237,82 +627,270 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamDTO"
+                            "$ref": "#/definitions/dto.PlayerDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Player version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a team's details by its ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/sync-jobs/{id}": {
+            "get": {
+                "description": "Get the state, timings and outcome of a team sync job",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teams"
+                    "sync-jobs"
                 ],
-                "summary": "Update a team by ID",
+                "summary": "Get a sync job by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Team ID",
+                        "description": "Sync job ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SyncJobDTO"
+                        }
                     },
-                    {
-                        "description": "Team data",
-                        "name": "team",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamDTO"
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams": {
+            "get": {
+                "description": "Get a page of teams, filtered and sorted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get all teams",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the name, ignoring the case and the diacritics",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the teams have a logo",
+                        "name": "has_logo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of a player on the roster of the teams",
+                        "name": "player_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields among id and name, descending when prefixed by -",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of teams to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of teams, 50 by default and at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, from the Link header",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamDTO"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.TeamDTO"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the first, previous, next and last pages"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Number of teams matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Delete a team by its ID",
+            "post": {
+                "description": "Create a new team with the provided details, synced with its provider when the provider finds it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "teams"
                 ],
-                "summary": "Delete a team by ID",
+                "summary": "Create a new team",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Team ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Team data",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamDTO"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
                     }
                 }
             }
         },
-        "/teams/{id}/players": {
+        "/teams/repair": {
+            "post": {
+                "description": "Remove the references to missing players, and duplicated references, from every team roster",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Repair team rosters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RosterRepairDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}": {
             "get": {
-                "description": "Get all players for a specific team by its ID",
+                "description": "Get a team by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "teams"
                 ],
-                "summary": "Get players by team ID",
+                "summary": "Get a team by ID",
                 "parameters": [
                     {
                         "type": "integer",
@@ -326,16 +904,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PlayerDTO"
+                            "$ref": "#/definitions/dto.TeamDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Team version"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
                     }
                 }
             },
-            "patch": {
-                "description": "Add a new player to a specific team by its ID",
+            "put": {
+                "description": "Update a team's details by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -345,7 +944,7 @@ const docTemplate = `{
                 "tags": [
                     "teams"
                 ],
-                "summary": "Add a new player to a specific team",
+                "summary": "Update a team by ID",
                 "parameters": [
                     {
                         "type": "integer",
@@ -355,12 +954,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Player data",
-                        "name": "player",
+                        "type": "string",
+                        "description": "Expected team version",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Team data",
+                        "name": "team",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PlayerDTO"
+                            "$ref": "#/definitions/dto.TeamDTO"
                         }
                     }
                 ],
@@ -368,22 +973,121 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PlayerDTO"
+                            "$ref": "#/definitions/dto.TeamDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Team version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a team by its ID",
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Delete a team by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected team version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
             }
         },
-        "/teams/{id}/sync": {
-            "post": {
-                "description": "Sync a team's data and its players with the data from a 3rd party API",
+        "/teams/{id}/history": {
+            "get": {
+                "description": "Get the changes of a team, most recent first, with the actor, the source and the field-level differences",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "teams"
                 ],
-                "summary": "Sync team data and players with 3rd party API",
+                "summary": "Get the history of a team",
                 "parameters": [
                     {
                         "type": "integer",
@@ -391,49 +1095,1092 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries, 50 by default and at most 200",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.TeamDTO"
+                            "$ref": "#/definitions/dto.HistoryPageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.PlayerDTO": {
-            "type": "object",
-            "required": [
-                "first_name"
-            ],
-            "properties": {
-                "first_name": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "last_name": {
-                    "type": "string"
-                },
-                "profile_picture": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.TeamDTO": {
+        "/teams/{id}/players": {
+            "get": {
+                "description": "Get all players for a specific team by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get players by team ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PlayerDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Add a new player to a specific team by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Add a new player to a specific team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Player data",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlayerDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/restore": {
+            "post": {
+                "description": "Restore a team from the trash, with its roster and the deleted players in it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Restore a deleted team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TeamDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Team version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/sync": {
+            "post": {
+                "description": "Queue a job syncing a team's data and its players with the data from its sports data provider. The job fails without changing anything when the provider squad is empty, or when more players left it than SYNC_MAX_DEPARTURES percent of the roster, unless forced. A team whose sync is already queued or running gets that job back.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Sync team data and players with its provider",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Remove the players that left the squad even when the squad is empty or most players left it",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SyncJobDTO"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the sync job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/sync/apply": {
+            "post": {
+                "description": "Look up a team on its provider again and apply the selected items of the sync diff all-or-nothing, failing when an item is no longer in the diff. What happens to the removed players depends on SYNC_DEPARTURE_POLICY: ` + "`" + `detach` + "`" + ` and ` + "`" + `keep` + "`" + ` leave them as free agents, ` + "`" + `archive` + "`" + ` deletes the ones on no other roster. Removing players fails when the provider squad is empty, or when more players are removed than SYNC_MAX_DEPARTURES percent of the roster, unless ` + "`" + `force` + "`" + ` is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Apply selected items of the sync of a team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Items of the diff to apply",
+                        "name": "selection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SyncSelectionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SyncReportDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/sync/preview": {
+            "get": {
+                "description": "Look up a team on its provider and return what a sync could change, without changing anything: the logo, the players to add, the players whose name or picture differ, and the players missing upstream",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Preview the sync of a team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SyncDiffDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "description": "Get the deleted teams and players that can still be restored, most recently deleted first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Get the trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TrashDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemDTO"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.CacheStatsDTO": {
+            "type": "object",
+            "properties": {
+                "backend": {
+                    "type": "string"
+                },
+                "coalesced": {
+                    "description": "Coalesced counts the lookups that waited for the same concurrent upstream call instead of making their own",
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "negative_hits": {
+                    "description": "NegativeHits counts the hits of cached \"not found\" responses",
+                    "type": "integer"
+                },
+                "upstream_calls": {
+                    "type": "integer"
+                },
+                "upstream_errors": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DuplicateCandidateDTO": {
+            "type": "object",
+            "properties": {
+                "duplicate_id": {
+                    "type": "integer"
+                },
+                "name_similarity": {
+                    "type": "number"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "same_date_of_birth": {
+                    "type": "boolean"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "shared_external_ids": {
+                    "description": "SharedExternalIDs are the providers the two players have the same ID at",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.DuplicateClusterDTO": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DuplicateCandidateDTO"
+                    }
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PlayerDTO"
+                    }
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.DuplicateClustersDTO": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DuplicateClusterDTO"
+                    }
+                }
+            }
+        },
+        "dto.FieldChangeDTO": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {},
+                "field": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FieldErrorDTO": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HistoryEntryDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldChangeDTO"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HistoryPageDTO": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.HistoryEntryDTO"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PlayerDTO": {
+            "type": "object",
+            "required": [
+                "first_name"
+            ],
+            "properties": {
+                "cutout": {
+                    "type": "string"
+                },
+                "date_of_birth": {
+                    "description": "DateOfBirth is formatted like 2006-01-02",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "DisplayName is the full name as spelled by the provider, KnownAs the single name of a mononym, and NativeName the\nname written in a non-Latin script",
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "edited_fields": {
+                    "description": "EditedFields are the fields the syncs leave as they are. The fields changed by an update are added to them,\nand an update giving them replaces the previous ones, so an empty list lets the syncs update every field again.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "external_ids": {
+                    "description": "ExternalIDs identify the player at the sports data providers, by provider name, and are set by the syncs",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "first_name": {
+                    "description": "FirstName holds the given names, and LastName the family name with its particles, empty for a mononym",
+                    "type": "string"
+                },
+                "height": {
+                    "description": "Height is in centimeters, and Weight in kilograms",
+                    "type": "integer",
+                    "maximum": 250,
+                    "minimum": 100
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "known_as": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "native_name": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "position": {
+                    "description": "The profile of the player, synced with its provider",
+                    "type": "string"
+                },
+                "preferred_foot": {
+                    "type": "string",
+                    "enum": [
+                        "left",
+                        "right",
+                        "both"
+                    ]
+                },
+                "profile_picture": {
+                    "type": "string"
+                },
+                "render": {
+                    "type": "string"
+                },
+                "shirt_number": {
+                    "type": "integer",
+                    "maximum": 99,
+                    "minimum": 1
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "retired",
+                        "loaned"
+                    ]
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer",
+                    "maximum": 200,
+                    "minimum": 30
+                }
+            }
+        },
+        "dto.PlayerMergeDTO": {
+            "type": "object",
+            "required": [
+                "player_ids"
+            ],
+            "properties": {
+                "player_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "dto.ProblemDTO": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldErrorDTO"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RosterRepairDTO": {
+            "type": "object",
+            "properties": {
+                "references_removed": {
+                    "type": "integer"
+                },
+                "teams_repaired": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncDiffDTO": {
+            "type": "object",
+            "properties": {
+                "external_id": {
+                    "description": "ExternalID links the team to the one found by name at the provider, and is applied by every sync",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.FieldChangeDTO"
+                        }
+                    ]
+                },
+                "logo": {
+                    "description": "Logo is the change of the team logo, absent when the logo is up to date or edited through the API",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.FieldChangeDTO"
+                        }
+                    ]
+                },
+                "players_incomplete": {
+                    "description": "PlayersIncomplete counts the players of the squad left without the height, weight and preferred foot looked up by\nID, which the preview does not look up",
+                    "type": "integer"
+                },
+                "players_missing_upstream": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SyncPlayerMatchDTO"
+                    }
+                },
+                "players_to_add": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SyncPlayerAddDTO"
+                    }
+                },
+                "players_to_update": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SyncPlayerDiffDTO"
+                    }
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "team_changes": {
+                    "description": "TeamChanges are the fields of the team profile differing from the provider, leaving out the fields edited through\nthe API and the ones missing upstream",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldChangeDTO"
+                    }
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncJobDTO": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "players_archived": {
+                    "description": "PlayersArchived counts the removed players deleted by the archive departure policy",
+                    "type": "integer"
+                },
+                "players_created": {
+                    "type": "integer"
+                },
+                "players_incomplete": {
+                    "description": "PlayersIncomplete counts the players of the squad the sync could not look up the height, weight and preferred\nfoot of, their details being completed by a later sync",
+                    "type": "integer"
+                },
+                "players_removed": {
+                    "type": "integer"
+                },
+                "players_updated": {
+                    "type": "integer"
+                },
+                "queued_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State is queued, running, succeeded or failed",
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncPlayerAddDTO": {
+            "type": "object",
+            "properties": {
+                "cutout": {
+                    "type": "string"
+                },
+                "date_of_birth": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "preferred_foot": {
+                    "type": "string"
+                },
+                "profile_picture": {
+                    "type": "string"
+                },
+                "render": {
+                    "type": "string"
+                },
+                "shirt_number": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncPlayerDiffDTO": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldChangeDTO"
+                    }
+                },
+                "player_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncPlayerMatchDTO": {
+            "type": "object",
+            "properties": {
+                "external_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncReportDTO": {
+            "type": "object",
+            "properties": {
+                "players_archived": {
+                    "description": "PlayersArchived counts the removed players deleted by the archive departure policy",
+                    "type": "integer"
+                },
+                "players_created": {
+                    "type": "integer"
+                },
+                "players_incomplete": {
+                    "description": "PlayersIncomplete counts the players of the squad the sync could not look up the height, weight and preferred\nfoot of, their details being completed by a later sync",
+                    "type": "integer"
+                },
+                "players_removed": {
+                    "type": "integer"
+                },
+                "players_updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SyncSelectionDTO": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "description": "Add identifies the players to add to the roster, by external ID or by name",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fields": {
+                    "description": "Fields identifies the team changes to apply, by field",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "force": {
+                    "description": "Force removes the players even when the provider squad is empty, or when more players left than the maximum share",
+                    "type": "boolean"
+                },
+                "logo": {
+                    "description": "Logo applies the logo change",
+                    "type": "boolean"
+                },
+                "remove": {
+                    "description": "Remove identifies the players missing upstream to remove from the roster, archived with the archive departure policy",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "update": {
+                    "description": "Update identifies the players to update",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "dto.TeamDTO": {
             "type": "object",
             "required": [
-                "name"
+                "name",
+                "social_links"
             ],
             "properties": {
+                "alternate_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "badge": {
+                    "type": "string"
+                },
+                "banner": {
+                    "type": "string"
+                },
+                "colors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "country": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "edited_fields": {
+                    "description": "EditedFields are the synced fields the syncs leave as they are. The fields changed by an update are added to them,\nand an update giving them replaces the previous ones, so an empty list lets the syncs update every field again.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "external_ids": {
+                    "description": "ExternalIDs identify the team at the sports data providers, by provider name. The syncs link the team found by name,\nand setting them pins the team to another one. An update without them keeps the previous ones.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "fanart": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "formed_year": {
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "id": {
                     "type": "integer"
                 },
+                "jersey": {
+                    "type": "string"
+                },
+                "last_sync_status": {
+                    "type": "string"
+                },
+                "last_synced_at": {
+                    "type": "string"
+                },
+                "league": {
+                    "type": "string"
+                },
                 "logo": {
                     "type": "string"
                 },
@@ -445,6 +2192,57 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/dto.PlayerDTO"
                     }
+                },
+                "provider": {
+                    "description": "Provider is the sports data provider the team is synced with, the default one when empty",
+                    "type": "string"
+                },
+                "short_name": {
+                    "description": "The profile of the team, synced with its provider",
+                    "type": "string"
+                },
+                "social_links": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "sport": {
+                    "type": "string"
+                },
+                "stadium": {
+                    "type": "string"
+                },
+                "stadium_capacity": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "sync_schedule": {
+                    "description": "SyncSchedule is the cron schedule of the automatic syncs of the team, the global one when empty and none when \"off\"",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "website": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TrashDTO": {
+            "type": "object",
+            "properties": {
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PlayerDTO"
+                    }
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TeamDTO"
+                    }
                 }
             }
         }
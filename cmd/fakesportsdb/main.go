@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"scoreplay/internal/fakesportsdb"
+	"time"
+)
+
+// Serves a stand-in for TheSportsDB API from recorded fixtures, for the development without network access. Point
+// THESPORTSDB_API_URL at it, like "http://localhost:8999/api".
+func main() {
+	addr := flag.String("addr", ":8999", "address to listen on")
+	fixturesDir := flag.String("fixtures", "", "dir of the recorded fixtures, served before the sample ones")
+	recordURL := flag.String("record", "", "base URL of the API to record the missing fixtures from, like https://www.thesportsdb.com/api/v1/json/3")
+	latency := flag.Duration("latency", 0, "latency added to every response")
+	rateLimitRatio := flag.Float64("rate-limit-ratio", 0, "share of the responses answered with a 429")
+	serverErrorRatio := flag.Float64("server-error-ratio", 0, "share of the responses answered with a 500")
+	malformedRatio := flag.Float64("malformed-ratio", 0, "share of the responses with a truncated body")
+	retryAfter := flag.Duration("retry-after", 0, "delay requested by the 429 responses")
+	flag.Parse()
+
+	if *recordURL != "" && *fixturesDir == "" {
+		log.Fatal("Recording requires a fixtures dir")
+	}
+
+	server := fakesportsdb.NewServer(fakesportsdb.Config{
+		FixturesDir: *fixturesDir,
+		RecordURL:   *recordURL,
+		Faults: fakesportsdb.Faults{
+			Latency:          fakesportsdb.Duration(*latency),
+			RateLimitRatio:   *rateLimitRatio,
+			ServerErrorRatio: *serverErrorRatio,
+			MalformedRatio:   *malformedRatio,
+			RetryAfter:       fakesportsdb.Duration(*retryAfter),
+		},
+	})
+
+	log.Printf("Serving the fake TheSportsDB API on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, logRequests(server)))
+}
+
+// logRequests logs the requests with the status and the duration of their response.
+func logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), recorder.status, time.Since(start))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"github.com/joho/godotenv"
+	"log"
+	"os"
+	"scoreplay/internal/database"
+	"scoreplay/internal/repository"
+)
+
+const usage = "Usage: migrate up|down|status"
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal(usage)
+	}
+
+	// Load environment variables from .env file
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatalf("Error loading .env file")
+	}
+
+	dbConfig := database.NewConfigFromEnv()
+	db, err := database.Open(dbConfig)
+	if err != nil {
+		log.Fatalf("Error opening %s database: %v", dbConfig.Driver, err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, dbConfig.Driver, repository.Backfills...)
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		migrations, err := migrator.Up()
+		for _, migration := range migrations {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error applying migrations: %v", err)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		migration, err := migrator.Down()
+		if err != nil {
+			log.Fatalf("Error reverting migration: %v", err)
+		}
+		if migration == nil {
+			fmt.Println("No applied migrations")
+			return
+		}
+		fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Error reading migration status: %v", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
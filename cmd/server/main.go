@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 	"log"
+	"net/http"
+	"os"
+	"path/filepath"
 	_ "scoreplay/cmd/docs"
 	"scoreplay/internal/controller"
+	"scoreplay/internal/database"
 	"scoreplay/internal/mapper"
 	"scoreplay/internal/repository"
 	"scoreplay/internal/service"
 	"scoreplay/internal/service/thirdparty"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // @title ScorePlay API
@@ -28,24 +37,137 @@ func main() {
 	}
 
 	// Initialize repositories
-	teamRepo := repository.NewInMemoryTeamRepository()
-	playerRepo := repository.NewInMemoryPlayerRepository()
+	var repo repository.Repository
+	switch dbConfig := database.NewConfigFromEnv(); dbConfig.Driver {
+	case "memory":
+		journalDir := os.Getenv("MEMORY_JOURNAL_DIR")
+		if journalDir == "" {
+			repo = repository.NewInMemoryRepository()
+			break
+		}
+		journal, err := repository.OpenInMemoryJournal(journalDir, durationFromEnv("MEMORY_SNAPSHOT_INTERVAL", 5*time.Minute))
+		if err != nil {
+			log.Fatalf("Error opening journal: %v", err)
+		}
+		defer journal.Close()
+		repo = journal.Repository()
+	case database.DriverSQLite, database.DriverPostgres:
+		db, err := database.Open(dbConfig)
+		if err != nil {
+			log.Fatalf("Error opening %s database: %v", dbConfig.Driver, err)
+		}
+		defer db.Close()
+		if os.Getenv("DATABASE_AUTO_MIGRATE") != "false" {
+			migrateDatabase(db, dbConfig.Driver)
+		}
+		repo = repository.NewSQLRepository(db)
+	default:
+		log.Fatalf("Unsupported storage driver %q", dbConfig.Driver)
+	}
 
 	// Initialize mappers
 	teamMapper := mapper.NewDefaultTeamMapper()
 	playerMapper := mapper.NewDefaultPlayerMapper()
+	historyMapper := mapper.NewDefaultHistoryMapper()
+
+	// Initialize delete and departure policies
+	teamDeletePolicy, err := service.ParseDeletePolicy(os.Getenv("TEAM_DELETE_POLICY"))
+	if err != nil {
+		log.Fatalf("Error parsing TEAM_DELETE_POLICY: %v", err)
+	}
+	playerDeletePolicy, err := service.ParseDeletePolicy(os.Getenv("PLAYER_DELETE_POLICY"))
+	if err != nil {
+		log.Fatalf("Error parsing PLAYER_DELETE_POLICY: %v", err)
+	}
+	syncDeparturePolicy, err := service.ParseDeparturePolicy(os.Getenv("SYNC_DEPARTURE_POLICY"))
+	if err != nil {
+		log.Fatalf("Error parsing SYNC_DEPARTURE_POLICY: %v", err)
+	}
+
+	// Initialize the TheSportsDB quota, of the API key tier unless overridden
+	rateLimit, err := thirdparty.RateLimitForTier(os.Getenv("THESPORTSDB_TIER"))
+	if err != nil {
+		log.Fatalf("Error parsing THESPORTSDB_TIER: %v", err)
+	}
+	rateLimit.RequestsPerMinute = intFromEnv("THESPORTSDB_RATE_LIMIT", rateLimit.RequestsPerMinute)
+	rateLimit.Burst = intFromEnv("THESPORTSDB_RATE_LIMIT_BURST", rateLimit.Burst)
 
 	// Initialize services
-	theSportsDBService := thirdparty.NewDefaultTheSportsBDService()
-	teamService := service.NewDefaultTeamService(teamRepo, playerRepo, teamMapper, playerMapper, theSportsDBService)
-	playerService := service.NewDefaultPlayerService(playerRepo, playerMapper)
+	var theSportsDBService thirdparty.TheSportsDBService = thirdparty.NewDefaultTheSportsBDService(
+		&http.Client{Timeout: durationFromEnv("THESPORTSDB_TIMEOUT", 10*time.Second)},
+		thirdparty.RetryPolicy{
+			MaxAttempts: intFromEnv("THESPORTSDB_MAX_ATTEMPTS", thirdparty.DefaultRetryPolicy.MaxAttempts),
+			BaseDelay:   durationFromEnv("THESPORTSDB_RETRY_DELAY", thirdparty.DefaultRetryPolicy.BaseDelay),
+			MaxDelay:    durationFromEnv("THESPORTSDB_MAX_RETRY_DELAY", thirdparty.DefaultRetryPolicy.MaxDelay),
+		},
+		thirdparty.NewCircuitBreaker(intFromEnv("THESPORTSDB_BREAKER_THRESHOLD", 5), durationFromEnv("THESPORTSDB_BREAKER_COOLDOWN", 30*time.Second)),
+		thirdparty.NewRateLimiter(rateLimit, durationFromEnv("THESPORTSDB_RATE_LIMIT_MAX_WAIT", 30*time.Second)),
+	)
+
+	// Cache the third party lookups, unless disabled
+	var cacheMonitor thirdparty.CacheMonitor
+	cacheBackend := os.Getenv("THESPORTSDB_CACHE")
+	if cacheBackend == "" {
+		cacheBackend = "memory"
+	}
+	if cacheBackend != "none" {
+		cache, err := newCache(cacheBackend)
+		if err != nil {
+			log.Fatalf("Error creating %s cache: %v", cacheBackend, err)
+		}
+		cachingService := thirdparty.NewCachingTheSportsDBService(theSportsDBService, cache, cacheBackend,
+			durationFromEnv("THESPORTSDB_CACHE_TTL", time.Hour), durationFromEnv("THESPORTSDB_CACHE_NEGATIVE_TTL", 5*time.Minute),
+			durationFromEnv("THESPORTSDB_CACHE_CALL_TIMEOUT", time.Minute))
+		theSportsDBService = cachingService
+		cacheMonitor = cachingService
+	}
+
+	// Register the enabled sports data providers
+	providers, err := newProviderRegistry(theSportsDBService)
+	if err != nil {
+		log.Fatalf("Error registering sports data providers: %v", err)
+	}
+
+	teamService := service.NewDefaultTeamService(repo, teamMapper, playerMapper, historyMapper, providers, teamDeletePolicy,
+		syncDeparturePolicy, intFromEnv("SYNC_MAX_DEPARTURES", 50))
+	playerService := service.NewDefaultPlayerService(repo, playerMapper, historyMapper, playerDeletePolicy)
+	syncJobService := service.NewDefaultSyncJobService(teamService, durationFromEnv("SYNC_JOB_RETENTION", time.Hour),
+		intFromEnv("SYNC_QUEUE_SIZE", 1000))
+	duplicateService := service.NewDefaultDuplicateService(repo, playerMapper)
+	trashService := service.NewDefaultTrashService(repo, teamMapper, playerMapper, durationFromEnv("TRASH_RETENTION", 30*24*time.Hour))
+
+	// Repair rosters referencing missing players before serving requests
+	repairReport, err := teamService.RepairRosters(service.WithActor(context.Background(), "system"))
+	if err != nil {
+		log.Fatalf("Error repairing team rosters: %v", err)
+	}
+	if repairReport.ReferencesRemoved > 0 {
+		log.Printf("Removed %d dangling player references from %d team rosters", repairReport.ReferencesRemoved, repairReport.TeamsRepaired)
+	}
+
+	// Run the sync jobs in the background
+	syncJobService.StartWorkers(intFromEnv("SYNC_WORKERS", 2))
+
+	// Resync the teams on their schedule in the background
+	syncScheduler, err := newSyncScheduler(repo, syncJobService)
+	if err != nil {
+		log.Fatalf("Error parsing the sync schedule: %v", err)
+	}
+	go syncScheduler.Run(service.WithActor(context.Background(), "scheduler"))
+
+	// Purge the expired trash in the background
+	go trashService.PurgePeriodically(service.WithActor(context.Background(), "system"), durationFromEnv("TRASH_PURGE_INTERVAL", time.Hour))
 
 	// Initialize validator
-	validator := validator.New()
+	validator := controller.NewValidator()
 
 	// Initialize controllers
 	teamController := controller.NewTeamController(teamService, validator)
 	playerController := controller.NewPlayerController(playerService, validator)
+	duplicateController := controller.NewDuplicateController(duplicateService, validator)
+	trashController := controller.NewTrashController(trashService)
+	syncJobController := controller.NewSyncJobController(syncJobService)
+	monitoringController := controller.NewMonitoringController(cacheMonitor)
 
 	// Initialize Gin router
 	router := gin.Default()
@@ -54,10 +176,113 @@ func main() {
 	// Setup routes
 	teamController.RegisterRoutes(apiV1)
 	playerController.RegisterRoutes(apiV1)
+	duplicateController.RegisterRoutes(apiV1)
+	trashController.RegisterRoutes(apiV1)
+	syncJobController.RegisterRoutes(apiV1)
+	monitoringController.RegisterRoutes(apiV1)
 
 	// Swagger setup
 	apiV1.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Answer unknown routes with a problem too
+	router.NoRoute(controller.NoRoute)
+
 	// Run the server
 	router.Run(":5555")
 }
+
+// migrateDatabase applies the pending schema migrations before the repositories are used.
+func migrateDatabase(db *sql.DB, driver string) {
+	migrator, err := database.NewMigrator(db, driver, repository.Backfills...)
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+	migrations, err := migrator.Up()
+	if err != nil {
+		log.Fatalf("Error migrating database: %v", err)
+	}
+	for _, migration := range migrations {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+}
+
+// newProviderRegistry registers the providers enabled by SPORTS_DATA_PROVIDERS, TheSportsDB only by default, with the
+// default provider of the teams given by SPORTS_DATA_DEFAULT_PROVIDER, the first enabled one by default.
+func newProviderRegistry(theSportsDBService thirdparty.TheSportsDBService) (*thirdparty.ProviderRegistry, error) {
+	factories := map[string]func() thirdparty.SportsDataProvider{
+		thirdparty.TheSportsDBProviderName: func() thirdparty.SportsDataProvider {
+			return thirdparty.NewTheSportsDBProvider(theSportsDBService)
+		},
+	}
+
+	names := os.Getenv("SPORTS_DATA_PROVIDERS")
+	if names == "" {
+		names = thirdparty.TheSportsDBProviderName
+	}
+	var providers []thirdparty.SportsDataProvider
+	for _, name := range strings.Split(names, ",") {
+		factory, exists := factories[strings.TrimSpace(name)]
+		if !exists {
+			return nil, fmt.Errorf("unknown provider %q", name)
+		}
+		providers = append(providers, factory())
+	}
+
+	defaultProvider := os.Getenv("SPORTS_DATA_DEFAULT_PROVIDER")
+	if defaultProvider == "" {
+		defaultProvider = providers[0].Name()
+	}
+	return thirdparty.NewProviderRegistry(defaultProvider, providers...)
+}
+
+// newSyncScheduler creates the scheduler of the team syncs, on the schedule given by SYNC_SCHEDULE, every day at 03:00
+// UTC by default, "off" only syncing the teams having their own schedule.
+func newSyncScheduler(repo repository.Repository, syncJobService service.SyncJobService) (*service.SyncScheduler, error) {
+	spec := os.Getenv("SYNC_SCHEDULE")
+	if spec == "" {
+		spec = "0 3 * * *"
+	}
+	var schedule *service.CronSchedule
+	if spec != service.SyncScheduleOff {
+		var err error
+		if schedule, err = service.ParseCronSchedule(spec); err != nil {
+			return nil, err
+		}
+	}
+	return service.NewSyncScheduler(repo, syncJobService, schedule,
+		durationFromEnv("SYNC_SCHEDULE_SPREAD", 2*time.Second), durationFromEnv("SYNC_MIN_INTERVAL", time.Hour)), nil
+}
+
+// newCache creates the cache backend of the third party lookups.
+func newCache(backend string) (thirdparty.Cache, error) {
+	switch backend {
+	case "memory":
+		return thirdparty.NewInMemoryCache(), nil
+	case "file":
+		dir := os.Getenv("THESPORTSDB_CACHE_DIR")
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "scoreplay-cache")
+		}
+		return thirdparty.NewFileCache(dir)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", backend)
+	}
+}
+
+// durationFromEnv parses a duration environment variable, falling back to the default when it is unset or invalid.
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
+
+// intFromEnv parses an integer environment variable, falling back to the default when it is unset or invalid.
+func intFromEnv(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
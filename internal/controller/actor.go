@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"scoreplay/internal/service"
+)
+
+// actorHeader names the caller whose changes are recorded in the history.
+const actorHeader = "X-Actor"
+
+// requestContext returns the context of the request, carrying the actor named by the X-Actor header.
+func requestContext(ctx *gin.Context) context.Context {
+	return service.WithActor(ctx.Request.Context(), ctx.GetHeader(actorHeader))
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"scoreplay/internal/dto"
+	"scoreplay/internal/service"
+)
+
+type DuplicateController struct {
+	service   service.DuplicateService
+	validator *validator.Validate
+}
+
+func NewDuplicateController(service service.DuplicateService, validator *validator.Validate) *DuplicateController {
+	return &DuplicateController{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (c *DuplicateController) RegisterRoutes(router *gin.RouterGroup) {
+	playerGroup := router.Group("/players")
+	{
+		playerGroup.GET("/duplicates", c.GetDuplicates)
+	}
+}
+
+// GetDuplicates godoc
+// @Summary Get the suspected duplicate players
+// @Description Get the clusters of players suspected to be the same person, scored on the similarity of their names, their dates of birth and their IDs at the providers, highest score first
+// @Tags players
+// @Produce json
+// @Param min_score query number false "Score from 0 to 1 from which two players are suspected duplicates, 0.8 by default"
+// @Param team_id query int false "ID of a team a player of the clusters is on the roster of"
+// @Success 200 {object} dto.DuplicateClustersDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /players/duplicates [get]
+func (c *DuplicateController) GetDuplicates(ctx *gin.Context) {
+	var query dto.DuplicateQueryDTO
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	if err := c.validator.Struct(query); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	duplicates, err := c.service.GetDuplicates(requestContext(ctx), query)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, duplicates)
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// setETag exposes the version of an entity as a strong entity tag.
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion reads the version expected by the If-Match header, 0 when any version is accepted.
+// It returns false when the header can never match an entity tag, like a weak or malformed one.
+func ifMatchVersion(ctx *gin.Context) (int, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
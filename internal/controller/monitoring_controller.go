@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scoreplay/internal/service/thirdparty"
+)
+
+type MonitoringController struct {
+	cache thirdparty.CacheMonitor
+}
+
+// NewMonitoringController creates the controller exposing the statistics of the cache, nil when caching is disabled.
+func NewMonitoringController(cache thirdparty.CacheMonitor) *MonitoringController {
+	return &MonitoringController{
+		cache: cache,
+	}
+}
+
+func (c *MonitoringController) RegisterRoutes(router *gin.RouterGroup) {
+	monitoringGroup := router.Group("/monitoring")
+	{
+		monitoringGroup.GET("/cache", c.GetCacheStats)
+	}
+}
+
+// GetCacheStats godoc
+// @Summary Get the third party cache statistics
+// @Description Get the hits, misses and upstream calls of the cache of TheSportsDB lookups
+// @Tags monitoring
+// @Produce json
+// @Success 200 {object} dto.CacheStatsDTO
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Router /monitoring/cache [get]
+func (c *MonitoringController) GetCacheStats(ctx *gin.Context) {
+	if c.cache == nil {
+		respondProblem(ctx, http.StatusNotFound, "Caching is disabled")
+		return
+	}
+	ctx.JSON(http.StatusOK, c.cache.Stats())
+}
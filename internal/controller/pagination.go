@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// pageParams reads the offset and limit query parameters, 0 when they are absent.
+func pageParams(ctx *gin.Context) (int, int, bool) {
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, false
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		return 0, 0, false
+	}
+	return offset, limit, true
+}
+
+// setPaginationHeaders exposes the total count of a list in X-Total-Count, and the links to the other pages in Link.
+// A list requested with an offset links to the other offsets, otherwise it links to the cursor of the next page.
+func setPaginationHeaders(ctx *gin.Context, total int, offset int, limit int, nextCursor string) {
+	ctx.Header("X-Total-Count", strconv.Itoa(total))
+
+	var links []string
+	link := func(rel string, params map[string]string) {
+		url := *ctx.Request.URL
+		query := url.Query()
+		query.Del("offset")
+		query.Del("cursor")
+		for name, value := range params {
+			query.Set(name, value)
+		}
+		url.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, url.RequestURI(), rel))
+	}
+
+	link("first", nil)
+	if _, offsetPagination := ctx.GetQuery("offset"); !offsetPagination {
+		if nextCursor != "" {
+			link("next", map[string]string{"cursor": nextCursor})
+		}
+	} else {
+		if offset > 0 {
+			link("prev", map[string]string{"offset": strconv.Itoa(max(offset-limit, 0))})
+		}
+		if offset+limit < total {
+			link("next", map[string]string{"offset": strconv.Itoa(offset + limit)})
+		}
+		if total > 0 {
+			link("last", map[string]string{"offset": strconv.Itoa((total - 1) / limit * limit)})
+		}
+	}
+	ctx.Header("Link", strings.Join(links, ", "))
+}
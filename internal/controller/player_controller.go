@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"net/http"
+	"path"
 	"scoreplay/internal/dto"
+	"scoreplay/internal/exception"
 	"scoreplay/internal/service"
 	"strconv"
 )
@@ -29,23 +32,50 @@ func (c *PlayerController) RegisterRoutes(router *gin.RouterGroup) {
 		playerGroup.POST("", c.CreatePlayer)
 		playerGroup.PUT("/:id", c.UpdatePlayer)
 		playerGroup.DELETE("/:id", c.DeletePlayer)
+		playerGroup.POST("/:id/restore", c.RestorePlayer)
+		playerGroup.POST("/:id/merge", c.MergePlayers)
+		playerGroup.GET("/:id/history", c.GetPlayerHistory)
 	}
 }
 
 // GetPlayers godoc
 // @Summary Get all players
-// @Description Get a list of all players
+// @Description Get a page of players, filtered and sorted
 // @Tags players
 // @Produce json
+// @Param name query string false "Part of the name of the player, ignoring the case and the diacritics"
+// @Param last_name query string false "Last name, ignoring the case and the diacritics"
+// @Param team_id query int false "ID of a team the players are on the roster of"
+// @Param sort query string false "Comma separated fields among id, first_name and last_name, descending when prefixed by -"
+// @Param offset query int false "Number of players to skip"
+// @Param limit query int false "Maximum number of players, 50 by default and at most 200"
+// @Param cursor query string false "Cursor of the next page, from the Link header"
 // @Success 200 {array} dto.PlayerDTO
+// @Header 200 {int} X-Total-Count "Number of players matching the filters"
+// @Header 200 {string} Link "Links to the first, previous, next and last pages"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /players [get]
 func (c *PlayerController) GetPlayers(ctx *gin.Context) {
-	players, err := c.service.GetAllPlayers()
+	var query dto.PlayerQueryDTO
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	if err := c.validator.Struct(query); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	players, err := c.service.GetAllPlayers(requestContext(ctx), query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, players)
+	setPaginationHeaders(ctx, players.Total, players.Offset, players.Limit, players.NextCursor)
+	ctx.JSON(http.StatusOK, players.Players)
 }
 
 // GetPlayerByID godoc
@@ -55,22 +85,30 @@ func (c *PlayerController) GetPlayers(ctx *gin.Context) {
 // @Produce json
 // @Param id path int true "Player ID"
 // @Success 200 {object} dto.PlayerDTO
+// @Header 200 {string} ETag "Player version"
+// @Success 301 "Moved Permanently, to the player a merged duplicate was merged into"
+// @Header 301 {string} Location "URL of the player the duplicate was merged into"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /players/{id} [get]
 func (c *PlayerController) GetPlayerByID(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid player ID")
 		return
 	}
-	player, err := c.service.GetPlayerByID(id)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	player, err := c.service.GetPlayerByID(requestContext(ctx), id)
+	var merged exception.EntityMergedException
+	if errors.As(err, &merged) {
+		ctx.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(ctx.Request.URL.Path), strconv.Itoa(merged.MergedInto)))
 		return
 	}
-	if player == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+	if err != nil {
+		respondError(ctx, err)
 		return
 	}
+	setETag(ctx, player.Version)
 	ctx.JSON(http.StatusOK, player)
 }
 
@@ -82,22 +120,26 @@ func (c *PlayerController) GetPlayerByID(ctx *gin.Context) {
 // @Produce json
 // @Param player body dto.PlayerDTO true "Player data"
 // @Success 201 {object} dto.PlayerDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /players [post]
 func (c *PlayerController) CreatePlayer(ctx *gin.Context) {
 	var req dto.PlayerDTO
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
 	if err := c.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
-	playerCreated, err := c.service.CreatePlayer(req)
+	playerCreated, err := c.service.CreatePlayer(requestContext(ctx), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
 
@@ -111,36 +153,55 @@ func (c *PlayerController) CreatePlayer(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Player ID"
+// @Param If-Match header string false "Expected player version"
 // @Param player body dto.PlayerDTO true "Player data"
 // @Success 200 {object} dto.PlayerDTO
+// @Header 200 {string} ETag "Player version"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 409 {object} dto.ProblemDTO "Conflict"
+// @Failure 412 {object} dto.ProblemDTO "Precondition Failed"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /players/{id} [put]
 func (c *PlayerController) UpdatePlayer(ctx *gin.Context) {
 	id := ctx.Param("id")
 	var req dto.PlayerDTO
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
 	if err := c.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
 	playerID, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid player ID")
 		return
 	}
 
 	req.ID = playerID
 
-	playerUpdated, err := c.service.UpdatePlayer(req)
+	// The If-Match header takes precedence over the version in the body
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusPreconditionFailed, "Invalid If-Match header")
+		return
+	}
+	if version != 0 {
+		req.Version = version
+	}
+
+	playerUpdated, err := c.service.UpdatePlayer(requestContext(ctx), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
 
+	setETag(ctx, playerUpdated.Version)
 	ctx.JSON(http.StatusOK, playerUpdated)
 }
 
@@ -149,17 +210,138 @@ func (c *PlayerController) UpdatePlayer(ctx *gin.Context) {
 // @Description Delete a player by its ID
 // @Tags players
 // @Param id path int true "Player ID"
+// @Param If-Match header string false "Expected player version"
 // @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 409 {object} dto.ProblemDTO "Conflict"
+// @Failure 412 {object} dto.ProblemDTO "Precondition Failed"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /players/{id} [delete]
 func (c *PlayerController) DeletePlayer(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusPreconditionFailed, "Invalid If-Match header")
 		return
 	}
-	if err := c.service.DeletePlayer(id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err = c.service.DeletePlayer(requestContext(ctx), id, version)
+	if err != nil {
+		respondError(ctx, err)
 		return
 	}
 	ctx.Status(http.StatusNoContent)
 }
+
+// RestorePlayer godoc
+// @Summary Restore a deleted player
+// @Description Restore a player from the trash, without adding it back to the team rosters
+// @Tags players
+// @Produce json
+// @Param id path int true "Player ID"
+// @Success 200 {object} dto.PlayerDTO
+// @Header 200 {string} ETag "Player version"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 409 {object} dto.ProblemDTO "Conflict"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /players/{id}/restore [post]
+func (c *PlayerController) RestorePlayer(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+	player, err := c.service.RestorePlayer(requestContext(ctx), id)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, player.Version)
+	ctx.JSON(http.StatusOK, player)
+}
+
+// MergePlayers godoc
+// @Summary Merge duplicates into a player
+// @Description Merge duplicates into a player, which keeps its fields and takes the ones it misses and the IDs at the providers from the duplicates. The duplicates are replaced by the player on the team rosters and deleted, their IDs redirecting to the player.
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param id path int true "Player ID"
+// @Param If-Match header string false "Expected player version"
+// @Param merge body dto.PlayerMergeDTO true "Duplicates to merge"
+// @Success 200 {object} dto.PlayerDTO
+// @Header 200 {string} ETag "Player version"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 409 {object} dto.ProblemDTO "Conflict"
+// @Failure 412 {object} dto.ProblemDTO "Precondition Failed"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /players/{id}/merge [post]
+func (c *PlayerController) MergePlayers(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+	var req dto.PlayerMergeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	if err := c.validator.Struct(req); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusPreconditionFailed, "Invalid If-Match header")
+		return
+	}
+	player, err := c.service.MergePlayers(requestContext(ctx), id, version, req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, player.Version)
+	ctx.JSON(http.StatusOK, player)
+}
+
+// GetPlayerHistory godoc
+// @Summary Get the history of a player
+// @Description Get the changes of a player, most recent first, with the actor, the source and the field-level differences
+// @Tags players
+// @Produce json
+// @Param id path int true "Player ID"
+// @Param offset query int false "Number of entries to skip"
+// @Param limit query int false "Maximum number of entries, 50 by default and at most 200"
+// @Success 200 {object} dto.HistoryPageDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Player not found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /players/{id}/history [get]
+func (c *PlayerController) GetPlayerHistory(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+	offset, limit, ok := pageParams(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid offset or limit")
+		return
+	}
+	history, err := c.service.GetPlayerHistory(requestContext(ctx), id, offset, limit)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"scoreplay/internal/dto"
+	"scoreplay/internal/exception"
+	"strconv"
+	"strings"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem types, relative URIs identifying every kind of problem the API reports.
+const (
+	problemTypeBlank                 = "about:blank"
+	problemTypeInvalidRequest        = "/problems/invalid-request"
+	problemTypeValidation            = "/problems/validation"
+	problemTypeEntityNotFound        = "/problems/entity-not-found"
+	problemTypeReferentialIntegrity  = "/problems/referential-integrity"
+	problemTypeVersionConflict       = "/problems/version-conflict"
+	problemTypeThirdPartyNotFound    = "/problems/third-party-not-found"
+	problemTypeThirdPartyUnavailable = "/problems/third-party-unavailable"
+	problemTypeThirdPartyTimeout     = "/problems/third-party-timeout"
+	problemTypeThirdPartyRateLimited = "/problems/third-party-rate-limited"
+	problemTypeQueueFull             = "/problems/queue-full"
+)
+
+// NewValidator creates a validator naming the fields of validation errors after their JSON or query parameter names.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return validate
+}
+
+// NoRoute answers the requests matching no route with a problem.
+func NoRoute(ctx *gin.Context) {
+	respondProblem(ctx, http.StatusNotFound, "No route matches "+ctx.Request.Method+" "+ctx.Request.URL.Path)
+}
+
+// respondError translates an error of a service into the problem matching its domain exception,
+// any other error being an internal error whose details are only logged.
+func respondError(ctx *gin.Context, err error) {
+	var notFound exception.EntityNotFoundException
+	var integrity exception.ReferentialIntegrityException
+	var conflict exception.VersionConflictException
+	var invalid exception.InvalidArgumentException
+	var validationErrors validator.ValidationErrors
+	var thirdParty *exception.ThirdPartyException
+	var rateLimit exception.RateLimitException
+	var queueFull exception.QueueFullException
+
+	switch {
+	case errors.As(err, &validationErrors):
+		respondValidationErrors(ctx, validationErrors)
+	case errors.As(err, &notFound):
+		writeProblem(ctx, http.StatusNotFound, problemTypeEntityNotFound, "Entity Not Found", err.Error())
+	case errors.As(err, &integrity):
+		writeProblem(ctx, http.StatusConflict, problemTypeReferentialIntegrity, "Referential Integrity Violation", err.Error())
+	case errors.As(err, &conflict):
+		// A stale write fails the precondition the client sent, or conflicts with the current version otherwise
+		status := http.StatusConflict
+		if ctx.GetHeader("If-Match") != "" {
+			status = http.StatusPreconditionFailed
+		}
+		writeProblem(ctx, status, problemTypeVersionConflict, "Version Conflict", err.Error())
+	case errors.As(err, &invalid):
+		writeProblem(ctx, http.StatusBadRequest, problemTypeInvalidRequest, "Invalid Request", err.Error())
+	case errors.As(err, &rateLimit):
+		// Whole seconds, rounded up so the client does not retry too early
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimit.RetryAfter.Seconds()))))
+		writeProblem(ctx, http.StatusTooManyRequests, problemTypeThirdPartyRateLimited, "Third Party Rate Limited", err.Error())
+	case errors.As(err, &queueFull):
+		writeProblem(ctx, http.StatusServiceUnavailable, problemTypeQueueFull, "Queue Full", err.Error())
+	case errors.As(err, &thirdParty):
+		respondThirdPartyError(ctx, thirdParty)
+	case errors.Is(err, context.DeadlineExceeded):
+		writeProblem(ctx, http.StatusGatewayTimeout, problemTypeThirdPartyTimeout, "Third Party Timeout", err.Error())
+	default:
+		log.Printf("Error handling %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+		respondProblem(ctx, http.StatusInternalServerError, "An unexpected error occurred")
+	}
+}
+
+// respondInvalidRequest answers a request whose body or parameters cannot be bound or fail the validation.
+func respondInvalidRequest(ctx *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		respondValidationErrors(ctx, validationErrors)
+		return
+	}
+	writeProblem(ctx, http.StatusBadRequest, problemTypeInvalidRequest, "Invalid Request", err.Error())
+}
+
+// respondProblem answers with a problem only described by its status and detail.
+func respondProblem(ctx *gin.Context, status int, detail string) {
+	writeProblem(ctx, status, problemTypeBlank, http.StatusText(status), detail)
+}
+
+func respondValidationErrors(ctx *gin.Context, validationErrors validator.ValidationErrors) {
+	problem := newProblem(ctx, http.StatusUnprocessableEntity, problemTypeValidation, "Validation Failed",
+		"The request has invalid fields")
+	for _, fieldError := range validationErrors {
+		problem.Errors = append(problem.Errors, dto.FieldErrorDTO{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Message: fieldErrorMessage(fieldError),
+		})
+	}
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// respondThirdPartyError answers 422 when the third party API does not know the requested entity,
+// 503 while the calls to it are suspended, 504 when it did not answer in time, and 502 for any other failure.
+func respondThirdPartyError(ctx *gin.Context, err *exception.ThirdPartyException) {
+	switch err.StatusCode {
+	case http.StatusNotFound:
+		writeProblem(ctx, http.StatusUnprocessableEntity, problemTypeThirdPartyNotFound, "Third Party Entity Not Found", err.Error())
+	case http.StatusServiceUnavailable:
+		writeProblem(ctx, http.StatusServiceUnavailable, problemTypeThirdPartyUnavailable, "Third Party Unavailable", err.Error())
+	case http.StatusGatewayTimeout:
+		writeProblem(ctx, http.StatusGatewayTimeout, problemTypeThirdPartyTimeout, "Third Party Timeout", err.Error())
+	default:
+		writeProblem(ctx, http.StatusBadGateway, problemTypeThirdPartyUnavailable, "Third Party Unavailable", err.Error())
+	}
+}
+
+func writeProblem(ctx *gin.Context, status int, problemType string, title string, detail string) {
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(status, newProblem(ctx, status, problemType, title, detail))
+}
+
+func newProblem(ctx *gin.Context, status int, problemType string, title string, detail string) *dto.ProblemDTO {
+	return &dto.ProblemDTO{
+		Type:     problemType,
+		Title:    title,
+		Status:   status,
+		Detail:   detail,
+		Instance: ctx.Request.URL.RequestURI(),
+	}
+}
+
+func fieldErrorMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldError.Param())
+	default:
+		return fmt.Sprintf("must satisfy %s", strings.TrimSuffix(fieldError.Tag()+"="+fieldError.Param(), "="))
+	}
+}
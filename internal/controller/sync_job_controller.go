@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scoreplay/internal/service"
+	"strconv"
+)
+
+type SyncJobController struct {
+	service service.SyncJobService
+}
+
+func NewSyncJobController(service service.SyncJobService) *SyncJobController {
+	return &SyncJobController{
+		service: service,
+	}
+}
+
+func (c *SyncJobController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/teams/:id/sync", c.SyncTeam)
+	syncJobGroup := router.Group("/sync-jobs")
+	{
+		syncJobGroup.GET("/:id", c.GetSyncJob)
+	}
+}
+
+// SyncTeam godoc
+// @Summary Sync team data and players with its provider
+// @Description Queue a job syncing a team's data and its players with the data from its sports data provider. The job fails without changing anything when the provider squad is empty, or when more players left it than SYNC_MAX_DEPARTURES percent of the roster, unless forced. A team whose sync is already queued or running gets that job back.
+// @Tags teams
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param force query bool false "Remove the players that left the squad even when the squad is empty or most players left it"
+// @Success 202 {object} dto.SyncJobDTO
+// @Header 202 {string} Location "URL of the sync job"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 503 {object} dto.ProblemDTO "Service Unavailable"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /teams/{id}/sync [post]
+func (c *SyncJobController) SyncTeam(ctx *gin.Context) {
+	teamID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
+		return
+	}
+
+	requestCtx := requestContext(ctx)
+	if force := ctx.Query("force"); force != "" {
+		forced, err := strconv.ParseBool(force)
+		if err != nil {
+			respondProblem(ctx, http.StatusBadRequest, "Invalid force flag")
+			return
+		}
+		if forced {
+			requestCtx = service.WithForcedDepartures(requestCtx)
+		}
+	}
+
+	job, err := c.service.EnqueueTeamSync(requestCtx, teamID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", "/api/v1/sync-jobs/"+strconv.Itoa(job.ID))
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetSyncJob godoc
+// @Summary Get a sync job by ID
+// @Description Get the state, timings and outcome of a team sync job
+// @Tags sync-jobs
+// @Produce json
+// @Param id path int true "Sync job ID"
+// @Success 200 {object} dto.SyncJobDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /sync-jobs/{id} [get]
+func (c *SyncJobController) GetSyncJob(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid sync job ID")
+		return
+	}
+	job, err := c.service.GetSyncJob(requestContext(ctx), id)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
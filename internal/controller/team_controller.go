@@ -29,26 +29,54 @@ func (c *TeamController) RegisterRoutes(router *gin.RouterGroup) {
 		teamGroup.POST("", c.CreateTeam)
 		teamGroup.PUT("/:id", c.UpdateTeam)
 		teamGroup.DELETE("/:id", c.DeleteTeam)
+		teamGroup.POST("/:id/restore", c.RestoreTeam)
+		teamGroup.GET("/:id/history", c.GetTeamHistory)
+		teamGroup.GET("/:id/sync/preview", c.PreviewSync)
+		teamGroup.POST("/:id/sync/apply", c.ApplySync)
 		teamGroup.GET("/:id/players", c.GetPlayersByTeam)
 		teamGroup.PATCH("/:id/players", c.AddPlayerToTeam)
-		teamGroup.POST("/:id/sync", c.SyncTeam)
+		teamGroup.POST("/repair", c.RepairRosters)
 	}
 }
 
 // GetTeams godoc
 // @Summary Get all teams
-// @Description Get a list of all teams
+// @Description Get a page of teams, filtered and sorted
 // @Tags teams
 // @Produce json
+// @Param name query string false "Part of the name, ignoring the case and the diacritics"
+// @Param has_logo query bool false "Whether the teams have a logo"
+// @Param player_id query int false "ID of a player on the roster of the teams"
+// @Param sort query string false "Comma separated fields among id and name, descending when prefixed by -"
+// @Param offset query int false "Number of teams to skip"
+// @Param limit query int false "Maximum number of teams, 50 by default and at most 200"
+// @Param cursor query string false "Cursor of the next page, from the Link header"
 // @Success 200 {array} dto.TeamDTO
+// @Header 200 {int} X-Total-Count "Number of teams matching the filters"
+// @Header 200 {string} Link "Links to the first, previous, next and last pages"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams [get]
 func (c *TeamController) GetTeams(ctx *gin.Context) {
-	teams, err := c.service.GetAllTeams()
+	var query dto.TeamQueryDTO
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	if err := c.validator.Struct(query); err != nil {
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	teams, err := c.service.GetAllTeams(requestContext(ctx), query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, teams)
+	setPaginationHeaders(ctx, teams.Total, teams.Offset, teams.Limit, teams.NextCursor)
+	ctx.JSON(http.StatusOK, teams.Teams)
 }
 
 // GetTeamByID godoc
@@ -58,49 +86,53 @@ func (c *TeamController) GetTeams(ctx *gin.Context) {
 // @Produce json
 // @Param id path int true "Team ID"
 // @Success 200 {object} dto.TeamDTO
+// @Header 200 {string} ETag "Team version"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams/{id} [get]
 func (c *TeamController) GetTeamByID(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
-	team, err := c.service.GetTeamByID(id)
+	team, err := c.service.GetTeamByID(requestContext(ctx), id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if team == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		respondError(ctx, err)
 		return
 	}
+	setETag(ctx, team.Version)
 	ctx.JSON(http.StatusOK, team)
 }
 
 // CreateTeam godoc
 // @Summary Create a new team
-// @Description Create a new team with the provided details
+// @Description Create a new team with the provided details, synced with its provider when the provider finds it
 // @Tags teams
 // @Accept json
 // @Produce json
 // @Param team body dto.TeamDTO true "Team data"
 // @Success 201 {object} dto.TeamDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams [post]
 func (c *TeamController) CreateTeam(ctx *gin.Context) {
 	var req dto.TeamDTO
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
 	if err := c.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
-	teamCreated, err := c.service.CreateTeam(req)
+	teamCreated, err := c.service.CreateTeam(requestContext(ctx), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
 
@@ -114,36 +146,55 @@ func (c *TeamController) CreateTeam(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Team ID"
+// @Param If-Match header string false "Expected team version"
 // @Param team body dto.TeamDTO true "Team data"
 // @Success 200 {object} dto.TeamDTO
+// @Header 200 {string} ETag "Team version"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 409 {object} dto.ProblemDTO "Conflict"
+// @Failure 412 {object} dto.ProblemDTO "Precondition Failed"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams/{id} [put]
 func (c *TeamController) UpdateTeam(ctx *gin.Context) {
 	id := ctx.Param("id")
 	var req dto.TeamDTO
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
 	if err := c.validator.Struct(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
 	teamID, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
 	req.ID = teamID
 
-	teamUpdated, err := c.service.UpdateTeam(req)
+	// The If-Match header takes precedence over the version in the body
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusPreconditionFailed, "Invalid If-Match header")
+		return
+	}
+	if version != 0 {
+		req.Version = version
+	}
+
+	teamUpdated, err := c.service.UpdateTeam(requestContext(ctx), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
 
+	setETag(ctx, teamUpdated.Version)
 	ctx.JSON(http.StatusOK, teamUpdated)
 }
 
@@ -152,16 +203,28 @@ func (c *TeamController) UpdateTeam(ctx *gin.Context) {
 // @Description Delete a team by its ID
 // @Tags teams
 // @Param id path int true "Team ID"
+// @Param If-Match header string false "Expected team version"
 // @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 409 {object} dto.ProblemDTO "Conflict"
+// @Failure 412 {object} dto.ProblemDTO "Precondition Failed"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams/{id} [delete]
 func (c *TeamController) DeleteTeam(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
-	if err := c.service.DeleteTeam(id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusPreconditionFailed, "Invalid If-Match header")
+		return
+	}
+	err = c.service.DeleteTeam(requestContext(ctx), id, version)
+	if err != nil {
+		respondError(ctx, err)
 		return
 	}
 	ctx.Status(http.StatusNoContent)
@@ -174,18 +237,21 @@ func (c *TeamController) DeleteTeam(ctx *gin.Context) {
 // @Produce json
 // @Param id path int true "Team ID"
 // @Success 200 {array} dto.PlayerDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams/{id}/players [get]
 func (c *TeamController) GetPlayersByTeam(ctx *gin.Context) {
 	id := ctx.Param("id")
 	teamID, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
-	players, err := c.service.GetTeamPlayers(teamID)
+	players, err := c.service.GetTeamPlayers(requestContext(ctx), teamID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, players)
@@ -199,48 +265,179 @@ func (c *TeamController) GetPlayersByTeam(ctx *gin.Context) {
 // @Produce json
 // @Param id path int true "Team ID"
 // @Param player body dto.PlayerDTO true "Player data"
-// @Success 200 {object} dto.PlayerDTO
+// @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
 // @Router /teams/{id}/players [patch]
 func (c *TeamController) AddPlayerToTeam(ctx *gin.Context) {
 	teamID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
 	var playerDTO dto.PlayerDTO
 	if err := ctx.ShouldBindJSON(&playerDTO); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidRequest(ctx, err)
+		return
+	}
+
+	if err := c.validator.Struct(playerDTO); err != nil {
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
-	if err := c.service.AddPlayerToTeam(teamID, playerDTO); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.service.AddPlayerToTeam(requestContext(ctx), teamID, playerDTO); err != nil {
+		respondError(ctx, err)
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
 
-// SyncTeam godoc
-// @Summary Sync team data and players with 3rd party API
-// @Description Sync a team's data and its players with the data from a 3rd party API
+// PreviewSync godoc
+// @Summary Preview the sync of a team
+// @Description Look up a team on its provider and return what a sync could change, without changing anything: the logo, the players to add, the players whose name or picture differ, and the players missing upstream
 // @Tags teams
 // @Produce json
 // @Param id path int true "Team ID"
-// @Success 200 {object} dto.TeamDTO
-// @Router /teams/{id}/sync [post]
-func (c *TeamController) SyncTeam(ctx *gin.Context) {
-	teamID, err := strconv.Atoi(ctx.Param("id"))
+// @Success 200 {object} dto.SyncDiffDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 429 {object} dto.ProblemDTO "Too Many Requests"
+// @Failure 502 {object} dto.ProblemDTO "Bad Gateway"
+// @Failure 503 {object} dto.ProblemDTO "Service Unavailable"
+// @Failure 504 {object} dto.ProblemDTO "Gateway Timeout"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /teams/{id}/sync/preview [get]
+func (c *TeamController) PreviewSync(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
+		return
+	}
+	diff, err := c.service.PreviewSync(requestContext(ctx), id)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// ApplySync godoc
+// @Summary Apply selected items of the sync of a team
+// @Description Look up a team on its provider again and apply the selected items of the sync diff all-or-nothing, failing when an item is no longer in the diff. What happens to the removed players depends on SYNC_DEPARTURE_POLICY: `detach` and `keep` leave them as free agents, `archive` deletes the ones on no other roster. Removing players fails when the provider squad is empty, or when more players are removed than SYNC_MAX_DEPARTURES percent of the roster, unless `force` is set.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param selection body dto.SyncSelectionDTO true "Items of the diff to apply"
+// @Success 200 {object} dto.SyncReportDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 422 {object} dto.ProblemDTO "Unprocessable Entity"
+// @Failure 429 {object} dto.ProblemDTO "Too Many Requests"
+// @Failure 502 {object} dto.ProblemDTO "Bad Gateway"
+// @Failure 503 {object} dto.ProblemDTO "Service Unavailable"
+// @Failure 504 {object} dto.ProblemDTO "Gateway Timeout"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /teams/{id}/sync/apply [post]
+func (c *TeamController) ApplySync(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
 		return
 	}
 
-	if err := c.service.SyncTeam(teamID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var selection dto.SyncSelectionDTO
+	if err := ctx.ShouldBindJSON(&selection); err != nil {
+		respondInvalidRequest(ctx, err)
 		return
 	}
 
-	ctx.Status(http.StatusNoContent)
+	report, err := c.service.ApplySync(requestContext(ctx), id, selection)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// RepairRosters godoc
+// @Summary Repair team rosters
+// @Description Remove the references to missing players, and duplicated references, from every team roster
+// @Tags teams
+// @Produce json
+// @Success 200 {object} dto.RosterRepairDTO
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /teams/repair [post]
+func (c *TeamController) RepairRosters(ctx *gin.Context) {
+	report, err := c.service.RepairRosters(requestContext(ctx))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// RestoreTeam godoc
+// @Summary Restore a deleted team
+// @Description Restore a team from the trash, with its roster and the deleted players in it
+// @Tags teams
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {object} dto.TeamDTO
+// @Header 200 {string} ETag "Team version"
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Not Found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /teams/{id}/restore [post]
+func (c *TeamController) RestoreTeam(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
+		return
+	}
+	team, err := c.service.RestoreTeam(requestContext(ctx), id)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, team.Version)
+	ctx.JSON(http.StatusOK, team)
+}
+
+// GetTeamHistory godoc
+// @Summary Get the history of a team
+// @Description Get the changes of a team, most recent first, with the actor, the source and the field-level differences
+// @Tags teams
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param offset query int false "Number of entries to skip"
+// @Param limit query int false "Maximum number of entries, 50 by default and at most 200"
+// @Success 200 {object} dto.HistoryPageDTO
+// @Failure 400 {object} dto.ProblemDTO "Bad Request"
+// @Failure 404 {object} dto.ProblemDTO "Team not found"
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /teams/{id}/history [get]
+func (c *TeamController) GetTeamHistory(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid team ID")
+		return
+	}
+	offset, limit, ok := pageParams(ctx)
+	if !ok {
+		respondProblem(ctx, http.StatusBadRequest, "Invalid offset or limit")
+		return
+	}
+	history, err := c.service.GetTeamHistory(requestContext(ctx), id, offset, limit)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
 }
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"scoreplay/internal/service"
+)
+
+type TrashController struct {
+	service service.TrashService
+}
+
+func NewTrashController(service service.TrashService) *TrashController {
+	return &TrashController{
+		service: service,
+	}
+}
+
+func (c *TrashController) RegisterRoutes(router *gin.RouterGroup) {
+	trashGroup := router.Group("/trash")
+	{
+		trashGroup.GET("", c.GetTrash)
+	}
+}
+
+// GetTrash godoc
+// @Summary Get the trash
+// @Description Get the deleted teams and players that can still be restored, most recently deleted first
+// @Tags trash
+// @Produce json
+// @Success 200 {object} dto.TrashDTO
+// @Failure 500 {object} dto.ProblemDTO "Internal Server Error"
+// @Router /trash [get]
+func (c *TrashController) GetTrash(ctx *gin.Context) {
+	trash, err := c.service.GetTrash(requestContext(ctx))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, trash)
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+	"os"
+	"strings"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+type Config struct {
+	Driver string
+	DSN    string
+}
+
+// NewConfigFromEnv reads the database configuration, defaulting to the embedded SQLite driver.
+func NewConfigFromEnv() Config {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+	if driver == "" {
+		driver = DriverSQLite
+	}
+	return Config{
+		Driver: driver,
+		DSN:    os.Getenv("DATABASE_DSN"),
+	}
+}
+
+// Open opens a connection pool for the configured driver, the schema is managed by the Migrator.
+func Open(config Config) (*sql.DB, error) {
+	driver := config.Driver
+	dsn := config.DSN
+	switch driver {
+	case DriverSQLite:
+		if dsn == "" {
+			dsn = "scoreplay.db"
+		}
+		dsn = withSQLitePragmas(dsn)
+	case DriverPostgres:
+		if dsn == "" {
+			return nil, fmt.Errorf("database DSN is required for driver %s", driver)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", config.Driver)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if driver == DriverSQLite {
+		// SQLite only supports a single writer, serialize access through one connection
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// withSQLitePragmas enables foreign keys and a busy timeout on every SQLite connection.
+func withSQLitePragmas(dsn string) string {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Backfill fills the data of a migration SQL cannot compute, like names normalized in Go. It runs after the Up of the
+// migration of its version, in the same transaction, and the Down of the migration reverts it.
+type Backfill struct {
+	Version int
+	Fill    func(tx *sql.Tx) error
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies the migrations embedded for a driver and records them in the schema_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	backfills  map[int]func(tx *sql.Tx) error
+}
+
+// NewMigrator loads the migrations embedded for a driver, completed with the backfills of their versions.
+func NewMigrator(db *sql.DB, driver string, backfills ...Backfill) (*Migrator, error) {
+	migrations, err := loadMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	fills := make(map[int]func(tx *sql.Tx) error)
+	for _, backfill := range backfills {
+		known := slices.ContainsFunc(migrations, func(migration Migration) bool { return migration.Version == backfill.Version })
+		if !known {
+			return nil, fmt.Errorf("backfill of unknown migration %04d", backfill.Version)
+		}
+		fills[backfill.Version] = backfill.Fill
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		backfills:  fills,
+	}, nil
+}
+
+// Up applies every pending migration in version order and returns the ones applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []Migration
+	for _, migration := range m.migrations {
+		if _, exists := applied[migration.Version]; exists {
+			continue
+		}
+		err := m.inTransaction(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return err
+			}
+			if fill, exists := m.backfills[migration.Version]; exists {
+				if err := fill(tx); err != nil {
+					return err
+				}
+			}
+			_, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				migration.Version, migration.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return migrated, fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		migrated = append(migrated, migration)
+	}
+	return migrated, nil
+}
+
+// Down reverts the most recently applied migration, it returns nil when there is nothing to revert.
+func (m *Migrator) Down() (*Migration, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, exists := applied[migration.Version]; !exists {
+			continue
+		}
+		err := m.inTransaction(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("migration %04d_%s rollback failed: %w", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+	return nil, nil
+}
+
+// Status lists every known migration together with the time it was applied, if any.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if appliedAt, exists := applied[migration.Version]; exists {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) appliedVersions() (map[int]time.Time, error) {
+	if _, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) inTransaction(fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadMigrations reads the up and down scripts of a driver, ordered by version.
+func loadMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationFileName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(matches[1])
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %04d has conflicting names %q and %q", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -0,0 +1 @@
+ALTER TABLE team_players DROP CONSTRAINT IF EXISTS team_players_player_id_fkey;
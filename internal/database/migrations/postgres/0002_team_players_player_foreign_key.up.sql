@@ -0,0 +1,4 @@
+DELETE FROM team_players WHERE player_id NOT IN (SELECT id FROM players);
+
+ALTER TABLE team_players
+	ADD CONSTRAINT team_players_player_id_fkey FOREIGN KEY (player_id) REFERENCES players (id) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+ALTER TABLE players DROP COLUMN version;
+
+ALTER TABLE teams DROP COLUMN version;
@@ -0,0 +1,3 @@
+ALTER TABLE teams ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE players ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_history_entity;
+
+DROP TABLE IF EXISTS history;
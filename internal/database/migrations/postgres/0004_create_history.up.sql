@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS history (
+	id SERIAL PRIMARY KEY,
+	entity_type TEXT NOT NULL,
+	entity_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	source TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	changes TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_history_entity ON history (entity_type, entity_id);
@@ -0,0 +1,3 @@
+ALTER TABLE players DROP COLUMN deleted_at;
+
+ALTER TABLE teams DROP COLUMN deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE teams ADD COLUMN deleted_at TIMESTAMP;
+
+ALTER TABLE players ADD COLUMN deleted_at TIMESTAMP;
@@ -0,0 +1,5 @@
+ALTER TABLE teams DROP COLUMN last_sync_status;
+
+ALTER TABLE teams DROP COLUMN last_synced_at;
+
+ALTER TABLE teams DROP COLUMN sync_schedule;
@@ -0,0 +1,3 @@
+ALTER TABLE players DROP COLUMN edited_fields;
+
+ALTER TABLE players DROP COLUMN external_ids;
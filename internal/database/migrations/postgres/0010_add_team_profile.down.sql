@@ -0,0 +1,33 @@
+ALTER TABLE teams DROP COLUMN edited_fields;
+
+ALTER TABLE teams DROP COLUMN description;
+
+ALTER TABLE teams DROP COLUMN social_links;
+
+ALTER TABLE teams DROP COLUMN website;
+
+ALTER TABLE teams DROP COLUMN fanart;
+
+ALTER TABLE teams DROP COLUMN jersey;
+
+ALTER TABLE teams DROP COLUMN banner;
+
+ALTER TABLE teams DROP COLUMN badge;
+
+ALTER TABLE teams DROP COLUMN colors;
+
+ALTER TABLE teams DROP COLUMN stadium_capacity;
+
+ALTER TABLE teams DROP COLUMN stadium;
+
+ALTER TABLE teams DROP COLUMN country;
+
+ALTER TABLE teams DROP COLUMN league;
+
+ALTER TABLE teams DROP COLUMN sport;
+
+ALTER TABLE teams DROP COLUMN formed_year;
+
+ALTER TABLE teams DROP COLUMN alternate_names;
+
+ALTER TABLE teams DROP COLUMN short_name;
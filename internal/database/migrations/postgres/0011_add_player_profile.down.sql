@@ -0,0 +1,19 @@
+ALTER TABLE players DROP COLUMN render;
+
+ALTER TABLE players DROP COLUMN cutout;
+
+ALTER TABLE players DROP COLUMN status;
+
+ALTER TABLE players DROP COLUMN preferred_foot;
+
+ALTER TABLE players DROP COLUMN weight;
+
+ALTER TABLE players DROP COLUMN height;
+
+ALTER TABLE players DROP COLUMN date_of_birth;
+
+ALTER TABLE players DROP COLUMN nationality;
+
+ALTER TABLE players DROP COLUMN shirt_number;
+
+ALTER TABLE players DROP COLUMN position;
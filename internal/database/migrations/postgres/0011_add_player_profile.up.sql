@@ -0,0 +1,19 @@
+ALTER TABLE players ADD COLUMN position TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN shirt_number INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE players ADD COLUMN nationality TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN date_of_birth TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE players ADD COLUMN weight INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE players ADD COLUMN preferred_foot TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN status TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN cutout TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN render TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE players ADD COLUMN merged_into INTEGER;
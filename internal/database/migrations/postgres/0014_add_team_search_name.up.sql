@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN search_name TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_team_players_player_id;
+
+DROP TABLE IF EXISTS team_players;
+
+DROP TABLE IF EXISTS players;
+
+DROP TABLE IF EXISTS teams;
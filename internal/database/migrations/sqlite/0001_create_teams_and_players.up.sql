@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS teams (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	logo TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS players (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name TEXT NOT NULL,
+	last_name TEXT NOT NULL DEFAULT '',
+	profile_picture TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS team_players (
+	team_id INTEGER NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+	player_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (team_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_players_player_id ON team_players (player_id);
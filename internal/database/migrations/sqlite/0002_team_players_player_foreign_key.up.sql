@@ -0,0 +1,16 @@
+DELETE FROM team_players WHERE player_id NOT IN (SELECT id FROM players);
+
+CREATE TABLE team_players_new (
+	team_id INTEGER NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+	player_id INTEGER NOT NULL REFERENCES players (id) ON DELETE RESTRICT,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (team_id, position)
+);
+
+INSERT INTO team_players_new (team_id, player_id, position) SELECT team_id, player_id, position FROM team_players;
+
+DROP TABLE team_players;
+
+ALTER TABLE team_players_new RENAME TO team_players;
+
+CREATE INDEX idx_team_players_player_id ON team_players (player_id);
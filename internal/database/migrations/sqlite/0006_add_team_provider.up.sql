@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN provider TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE teams ADD COLUMN sync_schedule TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN last_synced_at TIMESTAMP;
+
+ALTER TABLE teams ADD COLUMN last_sync_status TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE players ADD COLUMN external_ids TEXT NOT NULL DEFAULT '{}';
+
+ALTER TABLE players ADD COLUMN edited_fields TEXT NOT NULL DEFAULT '[]';
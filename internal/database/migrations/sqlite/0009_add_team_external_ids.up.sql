@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN external_ids TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,33 @@
+ALTER TABLE teams ADD COLUMN short_name TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN alternate_names TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE teams ADD COLUMN formed_year INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE teams ADD COLUMN sport TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN league TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN country TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN stadium TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN stadium_capacity INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE teams ADD COLUMN colors TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE teams ADD COLUMN badge TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN banner TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN jersey TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN fanart TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE teams ADD COLUMN website TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN social_links TEXT NOT NULL DEFAULT '{}';
+
+ALTER TABLE teams ADD COLUMN description TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams ADD COLUMN edited_fields TEXT NOT NULL DEFAULT '[]';
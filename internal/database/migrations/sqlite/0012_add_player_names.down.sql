@@ -0,0 +1,9 @@
+ALTER TABLE players DROP COLUMN search_last_name;
+
+ALTER TABLE players DROP COLUMN search_name;
+
+ALTER TABLE players DROP COLUMN native_name;
+
+ALTER TABLE players DROP COLUMN known_as;
+
+ALTER TABLE players DROP COLUMN display_name;
@@ -0,0 +1,9 @@
+ALTER TABLE players ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN known_as TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN native_name TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN search_name TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE players ADD COLUMN search_last_name TEXT NOT NULL DEFAULT '';
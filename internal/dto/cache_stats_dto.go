@@ -0,0 +1,14 @@
+package dto
+
+type CacheStatsDTO struct {
+	Backend string `json:"backend"`
+	Entries int    `json:"entries"`
+	Hits    int64  `json:"hits"`
+	// NegativeHits counts the hits of cached "not found" responses
+	NegativeHits int64 `json:"negative_hits"`
+	Misses       int64 `json:"misses"`
+	// Coalesced counts the lookups that waited for the same concurrent upstream call instead of making their own
+	Coalesced      int64 `json:"coalesced"`
+	UpstreamCalls  int64 `json:"upstream_calls"`
+	UpstreamErrors int64 `json:"upstream_errors"`
+}
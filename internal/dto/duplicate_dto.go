@@ -0,0 +1,36 @@
+package dto
+
+type DuplicateQueryDTO struct {
+	// MinScore is the score from which two players are suspected duplicates, 0.8 by default
+	MinScore *float64 `form:"min_score" validate:"omitempty,min=0,max=1"`
+	// TeamID keeps the clusters with a player on the roster of the team
+	TeamID int `form:"team_id"`
+}
+
+type DuplicateClustersDTO struct {
+	Clusters []DuplicateClusterDTO `json:"clusters"`
+}
+
+// DuplicateClusterDTO is a group of players suspected to be the same person, linked by the candidate pairs scoring at
+// least the minimum score.
+type DuplicateClusterDTO struct {
+	Score      float64                 `json:"score"`
+	Players    []PlayerDTO             `json:"players"`
+	Candidates []DuplicateCandidateDTO `json:"candidates"`
+}
+
+// DuplicateCandidateDTO is a pair of suspected duplicates, with what their score is made of.
+type DuplicateCandidateDTO struct {
+	PlayerID        int     `json:"player_id"`
+	DuplicateID     int     `json:"duplicate_id"`
+	Score           float64 `json:"score"`
+	NameSimilarity  float64 `json:"name_similarity"`
+	SameDateOfBirth bool    `json:"same_date_of_birth"`
+	// SharedExternalIDs are the providers the two players have the same ID at
+	SharedExternalIDs []string `json:"shared_external_ids,omitempty"`
+}
+
+// PlayerMergeDTO lists the duplicates merged into a player.
+type PlayerMergeDTO struct {
+	PlayerIDs []int `json:"player_ids" validate:"required,min=1,dive,gt=0"`
+}
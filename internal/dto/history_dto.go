@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type HistoryEntryDTO struct {
+	ID        int              `json:"id"`
+	Action    string           `json:"action"`
+	Actor     string           `json:"actor"`
+	Source    string           `json:"source"`
+	Timestamp time.Time        `json:"timestamp"`
+	Changes   []FieldChangeDTO `json:"changes"`
+}
+
+type FieldChangeDTO struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+type HistoryPageDTO struct {
+	Entries []HistoryEntryDTO `json:"entries"`
+	Total   int               `json:"total"`
+	Offset  int               `json:"offset"`
+	Limit   int               `json:"limit"`
+}
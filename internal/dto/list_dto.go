@@ -0,0 +1,39 @@
+package dto
+
+// ListQueryDTO holds the sorting and pagination parameters of a list endpoint.
+type ListQueryDTO struct {
+	Sort   string `form:"sort"`
+	Offset int    `form:"offset" validate:"min=0"`
+	Limit  int    `form:"limit" validate:"min=0,max=200"`
+	Cursor string `form:"cursor"`
+}
+
+type TeamQueryDTO struct {
+	ListQueryDTO
+	Name     string `form:"name"`
+	HasLogo  *bool  `form:"has_logo"`
+	PlayerID int    `form:"player_id"`
+}
+
+type PlayerQueryDTO struct {
+	ListQueryDTO
+	Name     string `form:"name"`
+	LastName string `form:"last_name"`
+	TeamID   int    `form:"team_id"`
+}
+
+type TeamListDTO struct {
+	Teams      []TeamDTO
+	Total      int
+	Offset     int
+	Limit      int
+	NextCursor string
+}
+
+type PlayerListDTO struct {
+	Players    []PlayerDTO
+	Total      int
+	Offset     int
+	Limit      int
+	NextCursor string
+}
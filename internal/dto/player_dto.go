@@ -1,8 +1,36 @@
 package dto
 
+import "time"
+
 type PlayerDTO struct {
-	ID             int    `json:"id"`
-	FirstName      string `json:"first_name" validate:"required"`
-	LastName       string `json:"last_name,omitempty"`
-	ProfilePicture string `json:"profile_picture,omitempty"`
+	ID int `json:"id"`
+	// FirstName holds the given names, and LastName the family name with its particles, empty for a mononym
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name,omitempty"`
+	// DisplayName is the full name as spelled by the provider, KnownAs the single name of a mononym, and NativeName the
+	// name written in a non-Latin script
+	DisplayName    string `json:"display_name,omitempty" validate:"max=200"`
+	KnownAs        string `json:"known_as,omitempty" validate:"max=100"`
+	NativeName     string `json:"native_name,omitempty" validate:"max=200"`
+	ProfilePicture string `json:"profile_picture,omitempty" validate:"omitempty,url"`
+	// The profile of the player, synced with its provider
+	Position    string `json:"position,omitempty"`
+	ShirtNumber int    `json:"shirt_number,omitempty" validate:"omitempty,min=1,max=99"`
+	Nationality string `json:"nationality,omitempty"`
+	// DateOfBirth is formatted like 2006-01-02
+	DateOfBirth string `json:"date_of_birth,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	// Height is in centimeters, and Weight in kilograms
+	Height        int    `json:"height,omitempty" validate:"omitempty,min=100,max=250"`
+	Weight        int    `json:"weight,omitempty" validate:"omitempty,min=30,max=200"`
+	PreferredFoot string `json:"preferred_foot,omitempty" validate:"omitempty,oneof=left right both"`
+	Status        string `json:"status,omitempty" validate:"omitempty,oneof=active retired loaned"`
+	Cutout        string `json:"cutout,omitempty" validate:"omitempty,url"`
+	Render        string `json:"render,omitempty" validate:"omitempty,url"`
+	// ExternalIDs identify the player at the sports data providers, by provider name, and are set by the syncs
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	// EditedFields are the fields the syncs leave as they are. The fields changed by an update are added to them,
+	// and an update giving them replaces the previous ones, so an empty list lets the syncs update every field again.
+	EditedFields []string   `json:"edited_fields,omitempty" validate:"omitempty,dive,oneof=first_name last_name display_name known_as native_name profile_picture position shirt_number nationality date_of_birth height weight preferred_foot status cutout render"`
+	Version      int        `json:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,18 @@
+package dto
+
+// ProblemDTO describes an error with the problem details of RFC 7807.
+type ProblemDTO struct {
+	Type     string          `json:"type"`
+	Title    string          `json:"title"`
+	Status   int             `json:"status"`
+	Detail   string          `json:"detail,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+	Errors   []FieldErrorDTO `json:"errors,omitempty"`
+}
+
+// FieldErrorDTO describes a field of a request failing a validation rule.
+type FieldErrorDTO struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type RosterRepairDTO struct {
+	TeamsRepaired     int `json:"teams_repaired"`
+	ReferencesRemoved int `json:"references_removed"`
+}
@@ -0,0 +1,69 @@
+package dto
+
+// SyncDiffDTO is what a sync of a team with its provider would change, computed without changing anything.
+type SyncDiffDTO struct {
+	TeamID   int    `json:"team_id"`
+	Provider string `json:"provider"`
+	// ExternalID links the team to the one found by name at the provider, and is applied by every sync
+	ExternalID *FieldChangeDTO `json:"external_id,omitempty"`
+	// Logo is the change of the team logo, absent when the logo is up to date or edited through the API
+	Logo *FieldChangeDTO `json:"logo,omitempty"`
+	// TeamChanges are the fields of the team profile differing from the provider, leaving out the fields edited through
+	// the API and the ones missing upstream
+	TeamChanges            []FieldChangeDTO     `json:"team_changes"`
+	PlayersToAdd           []SyncPlayerAddDTO   `json:"players_to_add"`
+	PlayersToUpdate        []SyncPlayerDiffDTO  `json:"players_to_update"`
+	PlayersMissingUpstream []SyncPlayerMatchDTO `json:"players_missing_upstream"`
+	// PlayersIncomplete counts the players of the squad left without the height, weight and preferred foot looked up by
+	// ID, which the preview does not look up
+	PlayersIncomplete int `json:"players_incomplete"`
+}
+
+// SyncPlayerAddDTO is a player of the provider squad missing from the roster, identified by its external ID or its name,
+// with the profile it is created with.
+type SyncPlayerAddDTO struct {
+	ExternalID     string `json:"external_id,omitempty"`
+	Name           string `json:"name"`
+	ProfilePicture string `json:"profile_picture"`
+	Position       string `json:"position,omitempty"`
+	ShirtNumber    int    `json:"shirt_number,omitempty"`
+	Nationality    string `json:"nationality,omitempty"`
+	DateOfBirth    string `json:"date_of_birth,omitempty"`
+	Height         int    `json:"height,omitempty"`
+	Weight         int    `json:"weight,omitempty"`
+	PreferredFoot  string `json:"preferred_foot,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Cutout         string `json:"cutout,omitempty"`
+	Render         string `json:"render,omitempty"`
+}
+
+// SyncPlayerDiffDTO is a player of the roster whose name or profile differ from the provider, leaving out the fields
+// edited through the API, or that is not linked to the provider yet.
+type SyncPlayerDiffDTO struct {
+	PlayerID int              `json:"player_id"`
+	Changes  []FieldChangeDTO `json:"changes"`
+}
+
+// SyncPlayerMatchDTO is a player of the roster, identified by its ID. A player missing upstream with an external ID
+// left the provider squad, while one without was only ever added locally.
+type SyncPlayerMatchDTO struct {
+	PlayerID   int    `json:"player_id"`
+	ExternalID string `json:"external_id,omitempty"`
+	Name       string `json:"name"`
+}
+
+// SyncSelectionDTO selects the items of a sync diff to apply.
+type SyncSelectionDTO struct {
+	// Logo applies the logo change
+	Logo bool `json:"logo"`
+	// Fields identifies the team changes to apply, by field
+	Fields []string `json:"fields"`
+	// Add identifies the players to add to the roster, by external ID or by name
+	Add []string `json:"add"`
+	// Update identifies the players to update
+	Update []int `json:"update"`
+	// Remove identifies the players missing upstream to remove from the roster, archived with the archive departure policy
+	Remove []int `json:"remove"`
+	// Force removes the players even when the provider squad is empty, or when more players left than the maximum share
+	Force bool `json:"force"`
+}
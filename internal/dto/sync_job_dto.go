@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// SyncReportDTO counts the players changed by a team sync.
+type SyncReportDTO struct {
+	PlayersCreated int `json:"players_created"`
+	PlayersUpdated int `json:"players_updated"`
+	PlayersRemoved int `json:"players_removed"`
+	// PlayersArchived counts the removed players deleted by the archive departure policy
+	PlayersArchived int `json:"players_archived"`
+	// PlayersIncomplete counts the players of the squad the sync could not look up the height, weight and preferred
+	// foot of, their details being completed by a later sync
+	PlayersIncomplete int `json:"players_incomplete"`
+}
+
+type SyncJobDTO struct {
+	ID     int `json:"id"`
+	TeamID int `json:"team_id"`
+	// State is queued, running, succeeded or failed
+	State      string     `json:"state"`
+	QueuedAt   time.Time  `json:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	SyncReportDTO
+	Error string `json:"error,omitempty"`
+}
@@ -1,8 +1,41 @@
 package dto
 
+import "time"
+
 type TeamDTO struct {
-	ID      int         `json:"id"`
-	Name    string      `json:"name" validate:"required"`
-	Logo    string      `json:"logo,omitempty"`
-	Players []PlayerDTO `json:"players,omitempty"`
+	ID   int    `json:"id"`
+	Name string `json:"name" validate:"required"`
+	Logo string `json:"logo,omitempty"`
+	// The profile of the team, synced with its provider
+	ShortName       string            `json:"short_name,omitempty"`
+	AlternateNames  []string          `json:"alternate_names,omitempty"`
+	FormedYear      int               `json:"formed_year,omitempty" validate:"gte=0"`
+	Sport           string            `json:"sport,omitempty"`
+	League          string            `json:"league,omitempty"`
+	Country         string            `json:"country,omitempty"`
+	Stadium         string            `json:"stadium,omitempty"`
+	StadiumCapacity int               `json:"stadium_capacity,omitempty" validate:"gte=0"`
+	Colors          []string          `json:"colors,omitempty" validate:"omitempty,dive,hexcolor"`
+	Badge           string            `json:"badge,omitempty"`
+	Banner          string            `json:"banner,omitempty"`
+	Jersey          string            `json:"jersey,omitempty"`
+	Fanart          []string          `json:"fanart,omitempty"`
+	Website         string            `json:"website,omitempty"`
+	SocialLinks     map[string]string `json:"social_links,omitempty" validate:"omitempty,dive,keys,oneof=facebook twitter instagram youtube,endkeys,required"`
+	Description     string            `json:"description,omitempty"`
+	// EditedFields are the synced fields the syncs leave as they are. The fields changed by an update are added to them,
+	// and an update giving them replaces the previous ones, so an empty list lets the syncs update every field again.
+	EditedFields []string `json:"edited_fields,omitempty" validate:"omitempty,dive,oneof=logo short_name alternate_names formed_year sport league country stadium stadium_capacity colors badge banner jersey fanart website social_links description"`
+	// Provider is the sports data provider the team is synced with, the default one when empty
+	Provider string `json:"provider,omitempty"`
+	// ExternalIDs identify the team at the sports data providers, by provider name. The syncs link the team found by name,
+	// and setting them pins the team to another one. An update without them keeps the previous ones.
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	// SyncSchedule is the cron schedule of the automatic syncs of the team, the global one when empty and none when "off"
+	SyncSchedule   string      `json:"sync_schedule,omitempty"`
+	LastSyncedAt   *time.Time  `json:"last_synced_at,omitempty"`
+	LastSyncStatus string      `json:"last_sync_status,omitempty"`
+	Players        []PlayerDTO `json:"players,omitempty"`
+	Version        int         `json:"version"`
+	DeletedAt      *time.Time  `json:"deleted_at,omitempty"`
 }
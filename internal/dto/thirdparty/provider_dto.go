@@ -0,0 +1,44 @@
+package thirdparty
+
+// ProviderTeamDto represents a team as returned by any sports data provider.
+type ProviderTeamDto struct {
+	ExternalID      string            `json:"external_id"`
+	Name            string            `json:"name"`
+	Logo            string            `json:"logo"`
+	ShortName       string            `json:"short_name"`
+	AlternateNames  []string          `json:"alternate_names"`
+	FormedYear      int               `json:"formed_year"`
+	Sport           string            `json:"sport"`
+	League          string            `json:"league"`
+	Country         string            `json:"country"`
+	Stadium         string            `json:"stadium"`
+	StadiumCapacity int               `json:"stadium_capacity"`
+	Colors          []string          `json:"colors"`
+	Badge           string            `json:"badge"`
+	Banner          string            `json:"banner"`
+	Jersey          string            `json:"jersey"`
+	Fanart          []string          `json:"fanart"`
+	Website         string            `json:"website"`
+	SocialLinks     map[string]string `json:"social_links"`
+	Description     string            `json:"description"`
+}
+
+// ProviderPlayerDto represents a player as returned by any sports data provider.
+type ProviderPlayerDto struct {
+	ExternalID  string `json:"external_id"`
+	Name        string `json:"name"`
+	Thumbnail   string `json:"thumbnail"`
+	Position    string `json:"position"`
+	ShirtNumber int    `json:"shirt_number"`
+	Nationality string `json:"nationality"`
+	// DateOfBirth is formatted like 2006-01-02
+	DateOfBirth string `json:"date_of_birth"`
+	// Height is in centimeters, and Weight in kilograms
+	Height int `json:"height"`
+	Weight int `json:"weight"`
+	// PreferredFoot is left, right or both, and Status active, retired or loaned, empty when unknown
+	PreferredFoot string `json:"preferred_foot"`
+	Status        string `json:"status"`
+	Cutout        string `json:"cutout"`
+	Render        string `json:"render"`
+}
@@ -2,7 +2,33 @@ package thirdparty
 
 // TheSportsDBSearchTeamDto represents the team data from TheSportsDB API.
 type TheSportsDBSearchTeamDto struct {
-	TeamLogo string `json:"strTeamLogo"`
+	TeamID          string `json:"idTeam"`
+	TeamName        string `json:"strTeam"`
+	TeamShort       string `json:"strTeamShort"`
+	Alternate       string `json:"strAlternate"`
+	FormedYear      string `json:"intFormedYear"`
+	Sport           string `json:"strSport"`
+	League          string `json:"strLeague"`
+	Country         string `json:"strCountry"`
+	Stadium         string `json:"strStadium"`
+	StadiumCapacity string `json:"intStadiumCapacity"`
+	Colour1         string `json:"strColour1"`
+	Colour2         string `json:"strColour2"`
+	Colour3         string `json:"strColour3"`
+	TeamLogo        string `json:"strTeamLogo"`
+	TeamBadge       string `json:"strTeamBadge"`
+	TeamBanner      string `json:"strTeamBanner"`
+	TeamJersey      string `json:"strTeamJersey"`
+	TeamFanart1     string `json:"strTeamFanart1"`
+	TeamFanart2     string `json:"strTeamFanart2"`
+	TeamFanart3     string `json:"strTeamFanart3"`
+	TeamFanart4     string `json:"strTeamFanart4"`
+	Website         string `json:"strWebsite"`
+	Facebook        string `json:"strFacebook"`
+	Twitter         string `json:"strTwitter"`
+	Instagram       string `json:"strInstagram"`
+	Youtube         string `json:"strYoutube"`
+	DescriptionEN   string `json:"strDescriptionEN"`
 }
 
 // TheSportsDBSearchTeamResponseDto represents the response structure for searching teams.
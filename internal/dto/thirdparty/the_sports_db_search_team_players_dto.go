@@ -2,11 +2,27 @@ package thirdparty
 
 // TheSportsDBSearchPlayerDto represents the player data from TheSportsDB API.
 type TheSportsDBSearchPlayerDto struct {
-	PlayerName string `json:"strPlayer"`
-	Thumbnail  string `json:"strThumb"`
+	PlayerID    string `json:"idPlayer"`
+	PlayerName  string `json:"strPlayer"`
+	Thumbnail   string `json:"strThumb"`
+	Position    string `json:"strPosition"`
+	Number      string `json:"strNumber"`
+	Nationality string `json:"strNationality"`
+	DateBorn    string `json:"dateBorn"`
+	Height      string `json:"strHeight"`
+	Weight      string `json:"strWeight"`
+	Side        string `json:"strSide"`
+	Status      string `json:"strStatus"`
+	Cutout      string `json:"strCutout"`
+	Render      string `json:"strRender"`
 }
 
 // TheSportsDBSearchPlayerResponseDto represents the response structure for searching players.
 type TheSportsDBSearchPlayerResponseDto struct {
 	Players []TheSportsDBSearchPlayerDto `json:"player"`
 }
+
+// TheSportsDBLookupPlayerResponseDto represents the response structure for looking up a player by ID.
+type TheSportsDBLookupPlayerResponseDto struct {
+	Players []TheSportsDBSearchPlayerDto `json:"players"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type TrashDTO struct {
+	Teams   []TeamDTO   `json:"teams"`
+	Players []PlayerDTO `json:"players"`
+}
+
+type PurgeReportDTO struct {
+	TeamsPurged   int `json:"teams_purged"`
+	PlayersPurged int `json:"players_purged"`
+}
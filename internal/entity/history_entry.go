@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// HistoryEntry records a single change of a team or a player.
+type HistoryEntry struct {
+	ID         int           `json:"id"`
+	EntityType string        `json:"entity_type"`
+	EntityID   int           `json:"entity_id"`
+	Action     string        `json:"action"`
+	Actor      string        `json:"actor"`
+	Source     string        `json:"source"`
+	Timestamp  time.Time     `json:"timestamp"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// FieldChange holds the value of a field before and after a change, nil when the entity did not exist.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
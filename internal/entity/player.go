@@ -1,8 +1,36 @@
 package entity
 
+import "time"
+
 type Player struct {
-	ID             int    `json:"id"`
-	FirstName      string `json:"first_name"`
-	LastName       string `json:"last_name"`
+	ID int `json:"id"`
+	// FirstName holds the given names, and LastName the family name with its particles, empty for a mononym
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	// DisplayName is the full name as spelled by the provider, KnownAs the single name of a mononym, and NativeName the
+	// name written in a non-Latin script
+	DisplayName    string `json:"display_name"`
+	KnownAs        string `json:"known_as"`
+	NativeName     string `json:"native_name"`
 	ProfilePicture string `json:"profile_picture"`
+	// The profile of the player, synced with its provider
+	Position    string `json:"position"`
+	ShirtNumber int    `json:"shirt_number"`
+	Nationality string `json:"nationality"`
+	DateOfBirth string `json:"date_of_birth"`
+	// Height is in centimeters, and Weight in kilograms
+	Height        int    `json:"height"`
+	Weight        int    `json:"weight"`
+	PreferredFoot string `json:"preferred_foot"`
+	Status        string `json:"status"`
+	Cutout        string `json:"cutout"`
+	Render        string `json:"render"`
+	// ExternalIDs identify the player at the sports data providers, by provider name
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	// EditedFields are the fields edited through the API, which the syncs leave as they are
+	EditedFields []string `json:"edited_fields,omitempty"`
+	// MergedInto is the ID of the player a deleted duplicate was merged into, which its ID redirects to
+	MergedInto int        `json:"merged_into,omitempty"`
+	Version    int        `json:"version"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
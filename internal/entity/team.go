@@ -1,8 +1,38 @@
 package entity
 
+import "time"
+
 type Team struct {
-	ID      int    `json:"id"`
-	Name    string `json:"name"`
-	Logo    string `json:"logo"`
-	Players []int  `json:"players"`
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Logo string `json:"logo"`
+	// The profile of the team, synced with its provider
+	ShortName       string            `json:"short_name"`
+	AlternateNames  []string          `json:"alternate_names"`
+	FormedYear      int               `json:"formed_year"`
+	Sport           string            `json:"sport"`
+	League          string            `json:"league"`
+	Country         string            `json:"country"`
+	Stadium         string            `json:"stadium"`
+	StadiumCapacity int               `json:"stadium_capacity"`
+	Colors          []string          `json:"colors"`
+	Badge           string            `json:"badge"`
+	Banner          string            `json:"banner"`
+	Jersey          string            `json:"jersey"`
+	Fanart          []string          `json:"fanart"`
+	Website         string            `json:"website"`
+	SocialLinks     map[string]string `json:"social_links,omitempty"`
+	Description     string            `json:"description"`
+	// EditedFields are the synced fields edited through the API, which the syncs leave as they are
+	EditedFields []string `json:"edited_fields,omitempty"`
+	Provider     string   `json:"provider"`
+	// ExternalIDs identify the team at the sports data providers, by provider name
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	// SyncSchedule is the cron schedule of the automatic syncs of the team, the global one when empty
+	SyncSchedule   string     `json:"sync_schedule"`
+	LastSyncedAt   *time.Time `json:"last_synced_at,omitempty"`
+	LastSyncStatus string     `json:"last_sync_status,omitempty"`
+	Players        []int      `json:"players"`
+	Version        int        `json:"version"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,14 @@
+package exception
+
+import "fmt"
+
+// EntityMergedException reports an entity that was merged into another one, which replaces it.
+type EntityMergedException struct {
+	Entity     string
+	ID         int
+	MergedInto int
+}
+
+func (e EntityMergedException) Error() string {
+	return fmt.Sprintf("Entity %s with ID %d was merged into %d", e.Entity, e.ID, e.MergedInto)
+}
@@ -0,0 +1,12 @@
+package exception
+
+import "fmt"
+
+type InvalidArgumentException struct {
+	Argument string
+	Reason   string
+}
+
+func (e InvalidArgumentException) Error() string {
+	return fmt.Sprintf("Invalid %s: %s", e.Argument, e.Reason)
+}
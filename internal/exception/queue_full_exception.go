@@ -0,0 +1,13 @@
+package exception
+
+import "fmt"
+
+// QueueFullException reports work refused because its queue already holds as many items as it can.
+type QueueFullException struct {
+	Queue string
+	Size  int
+}
+
+func (e QueueFullException) Error() string {
+	return fmt.Sprintf("Queue %s is full with %d items, retry later", e.Queue, e.Size)
+}
@@ -0,0 +1,21 @@
+package exception
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimitException reports a call refused by the request quota of a third party API, it can be retried after RetryAfter.
+type RateLimitException struct {
+	Source     string
+	RetryAfter time.Duration
+}
+
+func (e RateLimitException) Error() string {
+	return fmt.Sprintf("Rate limit exceeded: source %s, retry after %s", e.Source, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Retryable tells the call can succeed once the quota is replenished.
+func (e RateLimitException) Retryable() bool {
+	return true
+}
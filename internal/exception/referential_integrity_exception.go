@@ -0,0 +1,13 @@
+package exception
+
+import "fmt"
+
+type ReferentialIntegrityException struct {
+	Entity string
+	ID     int
+	Reason string
+}
+
+func (e ReferentialIntegrityException) Error() string {
+	return fmt.Sprintf("Entity %s with ID %d %s", e.Entity, e.ID, e.Reason)
+}
@@ -8,12 +8,17 @@ type ThirdPartyException struct {
 	Source     string
 	StatusCode int
 	Message    string
+	Cause      error
 }
 
 func (e *ThirdPartyException) Error() string {
 	return fmt.Sprintf("Third party error: source %s, status code %d, message: %s", e.Source, e.StatusCode, e.Message)
 }
 
+func (e *ThirdPartyException) Unwrap() error {
+	return e.Cause
+}
+
 func NewThirdPartyException(source string, statusCode int, message string) *ThirdPartyException {
 	return &ThirdPartyException{
 		Source:     source,
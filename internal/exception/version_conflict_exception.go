@@ -0,0 +1,14 @@
+package exception
+
+import "fmt"
+
+type VersionConflictException struct {
+	Entity          string
+	ID              int
+	ExpectedVersion int
+	ActualVersion   int
+}
+
+func (e VersionConflictException) Error() string {
+	return fmt.Sprintf("Entity %s with ID %d has version %d, expected version %d", e.Entity, e.ID, e.ActualVersion, e.ExpectedVersion)
+}
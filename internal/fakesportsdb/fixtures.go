@@ -0,0 +1,67 @@
+package fakesportsdb
+
+import (
+	"embed"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// sampleFixtures are a few sample responses, served when the fixtures dir has none for a request.
+//
+//go:embed fixtures
+var sampleFixtures embed.FS
+
+// fixtureStore reads the fixtures from a dir, then from the sample ones, and writes the recorded ones to the dir.
+type fixtureStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newFixtureStore(dir string) *fixtureStore {
+	return &fixtureStore{dir: dir}
+}
+
+// get returns the fixture of a key, from the dir first.
+func (s *fixtureStore) get(key string) ([]byte, bool) {
+	if s.dir != "" {
+		s.mu.Lock()
+		body, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+		s.mu.Unlock()
+		if err == nil {
+			return body, true
+		}
+	}
+	body, err := fs.ReadFile(sampleFixtures, "fixtures/"+key)
+	return body, err == nil
+}
+
+// put writes the fixture of a key to the dir.
+func (s *fixtureStore) put(key string, body []byte) error {
+	if s.dir == "" {
+		return nil
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, body, 0o644)
+}
+
+// fixtureKey returns the path of the fixture of a request, like "searchteams/t=arsenal.json", the endpoint being a
+// dir and the query the file name. The query values are trimmed and lowercased, as the API ignores their case.
+func fixtureKey(endpoint, rawQuery string) string {
+	query, _ := url.ParseQuery(rawQuery)
+	for name, values := range query {
+		for i, value := range values {
+			values[i] = strings.ToLower(strings.TrimSpace(value))
+		}
+		query[name] = values
+	}
+	return strings.TrimSuffix(endpoint, ".php") + "/" + query.Encode() + ".json"
+}
@@ -0,0 +1,252 @@
+package fakesportsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// emptyResults are the bodies TheSportsDB answers with when nothing matches, by endpoint.
+var emptyResults = map[string]string{
+	"searchteams.php":        `{"teams":null}`,
+	"lookupteam.php":         `{"teams":null}`,
+	"searchplayers.php":      `{"player":null}`,
+	"lookup_all_players.php": `{"player":null}`,
+	"lookupplayer.php":       `{"players":null}`,
+}
+
+// Config configures a fake server.
+type Config struct {
+	// FixturesDir holds the recorded fixtures, served before the sample ones and written by record mode
+	FixturesDir string
+	// RecordURL is the base URL of the API to record the missing fixtures from, like
+	// "https://www.thesportsdb.com/api/v1/json/3", record mode being off when empty
+	RecordURL string
+	// Faults are injected into the responses
+	Faults Faults
+}
+
+// Faults are injected into the responses of a fake server, the ratios being the share of the requests affected.
+type Faults struct {
+	Latency          Duration `json:"latency"`
+	RateLimitRatio   float64  `json:"rate_limit_ratio"`
+	ServerErrorRatio float64  `json:"server_error_ratio"`
+	MalformedRatio   float64  `json:"malformed_ratio"`
+	// RetryAfter is the delay requested by the 429 responses, none when zero
+	RetryAfter Duration `json:"retry_after"`
+}
+
+// Duration is a time.Duration written as a string like "250ms" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// Server is a stand-in for TheSportsDB API serving recorded JSON fixtures. The endpoints are recognized by the last
+// segment of the path, so the API version and key in the path are ignored and the server can be used as
+// THESPORTSDB_API_URL as is.
+type Server struct {
+	fixtures *fixtureStore
+	client   *http.Client
+	record   string
+
+	mu     sync.Mutex
+	faults Faults
+	random *rand.Rand
+}
+
+// NewServer creates a fake server with the config.
+func NewServer(config Config) *Server {
+	return &Server{
+		fixtures: newFixtureStore(config.FixturesDir),
+		client:   &http.Client{Timeout: 30 * time.Second},
+		record:   strings.TrimSuffix(config.RecordURL, "/"),
+		faults:   config.Faults,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Start starts a fake server with the config on a local port, for the tests. The caller closes it once done.
+func Start(config Config) (*httptest.Server, *Server) {
+	server := NewServer(config)
+	return httptest.NewServer(server), server
+}
+
+// Faults returns the faults currently injected.
+func (s *Server) Faults() Faults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults
+}
+
+// SetFaults replaces the faults injected into the next responses.
+func (s *Server) SetFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+}
+
+// ServeHTTP serves the fixture of the endpoint and query of the request, or the faults control on /_faults.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/_faults" {
+		s.serveFaults(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	faults, rateLimited, serverError, malformed := s.draw()
+	if faults.Latency > 0 {
+		select {
+		case <-time.After(time.Duration(faults.Latency)):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	switch {
+	case rateLimited:
+		if faults.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Duration(faults.RetryAfter).Seconds())))
+		}
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	case serverError:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	endpoint := path.Base(r.URL.Path)
+	if !strings.HasSuffix(endpoint, ".php") {
+		http.NotFound(w, r)
+		return
+	}
+	body, status := s.lookup(r.Context(), endpoint, r.URL.RawQuery)
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if malformed {
+		body = body[:len(body)/2]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// draw decides which faults the next response gets, a response being either rate limited or failed, and possibly
+// malformed when it is neither.
+func (s *Server) draw() (faults Faults, rateLimited, serverError, malformed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	faults = s.faults
+	roll := s.random.Float64()
+	rateLimited = roll < faults.RateLimitRatio
+	serverError = !rateLimited && roll < faults.RateLimitRatio+faults.ServerErrorRatio
+	malformed = s.random.Float64() < faults.MalformedRatio
+	return faults, rateLimited, serverError, malformed
+}
+
+// lookup returns the fixture of a request, recording it first in record mode. A request without fixture gets the
+// empty result of its endpoint, and an unknown endpoint a 404.
+func (s *Server) lookup(ctx context.Context, endpoint, rawQuery string) ([]byte, int) {
+	key := fixtureKey(endpoint, rawQuery)
+	if body, exists := s.fixtures.get(key); exists {
+		return body, http.StatusOK
+	}
+	if s.record != "" {
+		return s.recordFixture(ctx, endpoint, rawQuery, key)
+	}
+	if empty, exists := emptyResults[endpoint]; exists {
+		return []byte(empty), http.StatusOK
+	}
+	return nil, http.StatusNotFound
+}
+
+// recordFixture calls the recorded API and saves its response as the fixture of the request. The status of a failed
+// call is passed through, and a response not worth a fixture, like an empty result, is not saved.
+func (s *Server) recordFixture(ctx context.Context, endpoint, rawQuery, key string) ([]byte, int) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s?%s", s.record, endpoint, rawQuery), nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("Error recording %s: %v", key, err)
+		return nil, http.StatusBadGateway
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || !json.Valid(body) {
+		return nil, http.StatusBadGateway
+	}
+	if isEmptyResult(body) {
+		return body, http.StatusOK
+	}
+	if err := s.fixtures.put(key, body); err != nil {
+		log.Printf("Error saving fixture %s: %v", key, err)
+	} else {
+		log.Printf("Recorded fixture %s", key)
+	}
+	return body, http.StatusOK
+}
+
+// isEmptyResult tells if a response only holds null results, like {"teams":null}.
+func isEmptyResult(body []byte) bool {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false
+	}
+	for _, value := range result {
+		if string(value) != "null" {
+			return false
+		}
+	}
+	return true
+}
+
+// serveFaults returns the faults injected on GET, and replaces them on PUT.
+func (s *Server) serveFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var faults Faults
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.SetFaults(faults)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Faults())
+}
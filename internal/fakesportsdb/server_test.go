@@ -0,0 +1,139 @@
+package fakesportsdb
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServerServesTheFixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "searchteams"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "searchteams", "t=arsenal.json"), []byte(`{"teams":[{"idTeam":"1"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server, _ := Start(Config{FixturesDir: dir})
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "fixture of the dir", path: "/api/v1/json/3/searchteams.php?t=Arsenal", wantStatus: http.StatusOK, wantBody: `{"teams":[{"idTeam":"1"}]}`},
+		{name: "sample fixture", path: "/api/v1/json/3/searchteams.php?t=%20liverpool", wantStatus: http.StatusOK, wantBody: `"idTeam": "133602"`},
+		{name: "no fixture", path: "/api/v1/json/3/lookupplayer.php?id=1", wantStatus: http.StatusOK, wantBody: `{"players":null}`},
+		{name: "unknown endpoint", path: "/api/v1/json/3/eventsnext.php?id=1", wantStatus: http.StatusNotFound},
+		{name: "not an endpoint", path: "/api/v1/json/3", wantStatus: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, body := get(t, server.URL+test.path)
+			if status != test.wantStatus || !strings.Contains(body, test.wantBody) {
+				t.Errorf("got %d %s, want %d with %s", status, body, test.wantStatus, test.wantBody)
+			}
+		})
+	}
+}
+
+func TestServerInjectsTheFaults(t *testing.T) {
+	server, fake := Start(Config{})
+	defer server.Close()
+	url := server.URL + "/api/v1/json/3/lookupteam.php?id=133604"
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/_faults", strings.NewReader(`{"rate_limit_ratio": 1, "retry_after": "2s"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT /_faults: %v", err)
+	}
+	resp.Body.Close()
+	if faults := fake.Faults(); faults.RateLimitRatio != 1 || faults.RetryAfter != Duration(2*time.Second) {
+		t.Fatalf("faults = %+v, want the ones put", faults)
+	}
+	resp, err = http.Get(url)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("got %d with Retry-After %q, want 429 with 2", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	fake.SetFaults(Faults{ServerErrorRatio: 1})
+	if status, _ := get(t, url); status != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", status)
+	}
+
+	fake.SetFaults(Faults{MalformedRatio: 1})
+	if status, body := get(t, url); status != http.StatusOK || json.Valid([]byte(body)) {
+		t.Errorf("got %d %s, want a truncated body", status, body)
+	}
+
+	fake.SetFaults(Faults{Latency: Duration(100 * time.Millisecond)})
+	start := time.Now()
+	if status, _ := get(t, url); status != http.StatusOK || time.Since(start) < 100*time.Millisecond {
+		t.Errorf("got %d after %s, want 200 after 100ms", status, time.Since(start))
+	}
+
+	status, body := get(t, server.URL+"/_faults")
+	if status != http.StatusOK || !strings.Contains(body, `"latency":"100ms"`) {
+		t.Errorf("GET /_faults = %d %s, want the latency", status, body)
+	}
+}
+
+func TestServerRecordsTheMissingFixtures(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Query().Get("t") == "nowhere" {
+			w.Write([]byte(`{"teams":null}`))
+			return
+		}
+		w.Write([]byte(`{"teams":[{"idTeam":"42"}]}`))
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+	server, _ := Start(Config{FixturesDir: dir, RecordURL: upstream.URL + "/"})
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, body := get(t, server.URL+"/searchteams.php?t=Chelsea"); !strings.Contains(body, `"42"`) {
+			t.Errorf("body = %s, want the recorded team", body)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("upstream calls = %d, want 1 once recorded", calls.Load())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "searchteams", "t=chelsea.json")); err != nil {
+		t.Errorf("recorded fixture: %v", err)
+	}
+
+	get(t, server.URL+"/searchteams.php?t=nowhere")
+	if _, err := os.Stat(filepath.Join(dir, "searchteams", "t=nowhere.json")); !os.IsNotExist(err) {
+		t.Errorf("empty result recorded: %v", err)
+	}
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return resp.StatusCode, string(body)
+}
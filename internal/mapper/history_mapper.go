@@ -0,0 +1,40 @@
+package mapper
+
+import (
+	"scoreplay/internal/dto"
+	"scoreplay/internal/entity"
+)
+
+type DefaultHistoryMapper struct {
+}
+
+func NewDefaultHistoryMapper() *DefaultHistoryMapper {
+	return &DefaultHistoryMapper{}
+}
+
+func (m *DefaultHistoryMapper) MapToHistoryEntryDTO(entry entity.HistoryEntry) *dto.HistoryEntryDTO {
+	changes := []dto.FieldChangeDTO{}
+	for _, change := range entry.Changes {
+		changes = append(changes, dto.FieldChangeDTO{
+			Field:  change.Field,
+			Before: change.Before,
+			After:  change.After,
+		})
+	}
+	return &dto.HistoryEntryDTO{
+		ID:        entry.ID,
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		Source:    entry.Source,
+		Timestamp: entry.Timestamp,
+		Changes:   changes,
+	}
+}
+
+func (m *DefaultHistoryMapper) MapToHistoryEntryDTOList(entries []entity.HistoryEntry) []dto.HistoryEntryDTO {
+	historyEntryDTOList := []dto.HistoryEntryDTO{}
+	for _, entry := range entries {
+		historyEntryDTOList = append(historyEntryDTOList, *m.MapToHistoryEntryDTO(entry))
+	}
+	return historyEntryDTOList
+}
@@ -19,7 +19,13 @@ type PlayerMapper interface {
 	MapFromPlayerDTOList(playersDTO []dto.PlayerDTO) []entity.Player
 }
 
+type HistoryMapper interface {
+	MapToHistoryEntryDTO(entry entity.HistoryEntry) *dto.HistoryEntryDTO
+	MapToHistoryEntryDTOList(entries []entity.HistoryEntry) []dto.HistoryEntryDTO
+}
+
 type Mapper struct {
-	Team   TeamMapper
-	Player PlayerMapper
+	Team    TeamMapper
+	Player  PlayerMapper
+	History HistoryMapper
 }
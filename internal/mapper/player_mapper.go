@@ -17,7 +17,24 @@ func (m *DefaultPlayerMapper) MapToPlayerDTO(player entity.Player) *dto.PlayerDT
 		ID:             player.ID,
 		FirstName:      player.FirstName,
 		LastName:       player.LastName,
+		DisplayName:    player.DisplayName,
+		KnownAs:        player.KnownAs,
+		NativeName:     player.NativeName,
 		ProfilePicture: player.ProfilePicture,
+		Position:       player.Position,
+		ShirtNumber:    player.ShirtNumber,
+		Nationality:    player.Nationality,
+		DateOfBirth:    player.DateOfBirth,
+		Height:         player.Height,
+		Weight:         player.Weight,
+		PreferredFoot:  player.PreferredFoot,
+		Status:         player.Status,
+		Cutout:         player.Cutout,
+		Render:         player.Render,
+		ExternalIDs:    player.ExternalIDs,
+		EditedFields:   player.EditedFields,
+		Version:        player.Version,
+		DeletedAt:      player.DeletedAt,
 	}
 }
 
@@ -34,7 +51,22 @@ func (m *DefaultPlayerMapper) MapFromPlayerDTO(playerDTO dto.PlayerDTO) *entity.
 		ID:             playerDTO.ID,
 		FirstName:      playerDTO.FirstName,
 		LastName:       playerDTO.LastName,
+		DisplayName:    playerDTO.DisplayName,
+		KnownAs:        playerDTO.KnownAs,
+		NativeName:     playerDTO.NativeName,
 		ProfilePicture: playerDTO.ProfilePicture,
+		Position:       playerDTO.Position,
+		ShirtNumber:    playerDTO.ShirtNumber,
+		Nationality:    playerDTO.Nationality,
+		DateOfBirth:    playerDTO.DateOfBirth,
+		Height:         playerDTO.Height,
+		Weight:         playerDTO.Weight,
+		PreferredFoot:  playerDTO.PreferredFoot,
+		Status:         playerDTO.Status,
+		Cutout:         playerDTO.Cutout,
+		Render:         playerDTO.Render,
+		EditedFields:   playerDTO.EditedFields,
+		Version:        playerDTO.Version,
 	}
 }
 
@@ -14,9 +14,33 @@ func NewDefaultTeamMapper() *DefaultTeamMapper {
 
 func (m *DefaultTeamMapper) MapToTeamDTO(team entity.Team) *dto.TeamDTO {
 	return &dto.TeamDTO{
-		ID:   team.ID,
-		Name: team.Name,
-		Logo: team.Logo,
+		ID:              team.ID,
+		Name:            team.Name,
+		Logo:            team.Logo,
+		ShortName:       team.ShortName,
+		AlternateNames:  team.AlternateNames,
+		FormedYear:      team.FormedYear,
+		Sport:           team.Sport,
+		League:          team.League,
+		Country:         team.Country,
+		Stadium:         team.Stadium,
+		StadiumCapacity: team.StadiumCapacity,
+		Colors:          team.Colors,
+		Badge:           team.Badge,
+		Banner:          team.Banner,
+		Jersey:          team.Jersey,
+		Fanart:          team.Fanart,
+		Website:         team.Website,
+		SocialLinks:     team.SocialLinks,
+		Description:     team.Description,
+		EditedFields:    team.EditedFields,
+		Provider:        team.Provider,
+		ExternalIDs:     team.ExternalIDs,
+		SyncSchedule:    team.SyncSchedule,
+		LastSyncedAt:    team.LastSyncedAt,
+		LastSyncStatus:  team.LastSyncStatus,
+		Version:         team.Version,
+		DeletedAt:       team.DeletedAt,
 	}
 }
 
@@ -30,9 +54,30 @@ func (m *DefaultTeamMapper) MapToTeamDTOList(teams []entity.Team) []dto.TeamDTO
 
 func (m *DefaultTeamMapper) MapFromTeamDTO(teamDTO dto.TeamDTO) *entity.Team {
 	return &entity.Team{
-		ID:   teamDTO.ID,
-		Name: teamDTO.Name,
-		Logo: teamDTO.Logo,
+		ID:              teamDTO.ID,
+		Name:            teamDTO.Name,
+		Logo:            teamDTO.Logo,
+		ShortName:       teamDTO.ShortName,
+		AlternateNames:  teamDTO.AlternateNames,
+		FormedYear:      teamDTO.FormedYear,
+		Sport:           teamDTO.Sport,
+		League:          teamDTO.League,
+		Country:         teamDTO.Country,
+		Stadium:         teamDTO.Stadium,
+		StadiumCapacity: teamDTO.StadiumCapacity,
+		Colors:          teamDTO.Colors,
+		Badge:           teamDTO.Badge,
+		Banner:          teamDTO.Banner,
+		Jersey:          teamDTO.Jersey,
+		Fanart:          teamDTO.Fanart,
+		Website:         teamDTO.Website,
+		SocialLinks:     teamDTO.SocialLinks,
+		Description:     teamDTO.Description,
+		EditedFields:    teamDTO.EditedFields,
+		Provider:        teamDTO.Provider,
+		ExternalIDs:     teamDTO.ExternalIDs,
+		SyncSchedule:    teamDTO.SyncSchedule,
+		Version:         teamDTO.Version,
 	}
 }
 
@@ -0,0 +1,148 @@
+package names
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// particles start a family name when they follow the given names, like "van" in "Virgil van Dijk".
+var particles = map[string]struct{}{
+	"al": {}, "bin": {}, "da": {}, "das": {}, "de": {}, "del": {}, "della": {}, "der": {}, "des": {}, "di": {}, "do": {},
+	"dos": {}, "du": {}, "el": {}, "ibn": {}, "la": {}, "le": {}, "mac": {}, "ten": {}, "ter": {}, "van": {}, "von": {},
+}
+
+// folds are the letters that are not a base letter with diacritics, and so are not folded by the decomposition.
+var folds = strings.NewReplacer("ø", "o", "æ", "ae", "œ", "oe", "ß", "ss", "đ", "d", "ð", "d", "ł", "l", "ı", "i", "þ", "th",
+	"-", " ", "'", "", "’", "", ".", "")
+
+// Name is the name of a person, split into its parts.
+type Name struct {
+	// Given are the given names, and Family the family name with its particles, empty for a mononym
+	Given  string
+	Family string
+	// Display is the name as spelled by its source, with the spacing collapsed
+	Display string
+	// KnownAs is the single name a mononym is known as, like "Pepe"
+	KnownAs string
+	// Native is the name written in a non-Latin script, like "손흥민"
+	Native string
+}
+
+// Parse splits a full name into its parts. The family name starts at the first particle following the given names, or
+// is the last word otherwise, so "Virgil van Dijk" is Virgil and van Dijk, and "Luis Fernando Díaz" Luis Fernando
+// and Díaz. A single word is a mononym, kept as the given name too. A name in a non-Latin script is only kept as the
+// native name, as its words are not reliably given and family names, and a script without spaces has a single word.
+func Parse(fullName string) Name {
+	words := strings.Fields(fullName)
+	name := Name{Display: strings.Join(words, " ")}
+	if !isLatin(name.Display) {
+		name.Native = name.Display
+		return name
+	}
+	switch len(words) {
+	case 0:
+		return name
+	case 1:
+		name.Given, name.KnownAs = words[0], words[0]
+		return name
+	}
+	familyStart := len(words) - 1
+	for i := 1; i < len(words)-1; i++ {
+		if _, exists := particles[strings.ToLower(words[i])]; exists {
+			familyStart = i
+			break
+		}
+	}
+	name.Given = strings.Join(words[:familyStart], " ")
+	name.Family = strings.Join(words[familyStart:], " ")
+	return name
+}
+
+// Normalize returns the key comparing names regardless of the case, the diacritics, the punctuation and the spacing,
+// so "Ødegaard", "ødegaard" and "Odegaard" have the same key, and "Alexander-Arnold" the one of "Alexander Arnold".
+func Normalize(name string) string {
+	decomposed := norm.NFKD.String(folds.Replace(strings.ToLower(name)))
+	var builder strings.Builder
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			builder.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// isLatin tells if the letters of a name are all of the Latin script.
+func isLatin(name string) bool {
+	for _, r := range name {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Similarity scores from 0 to 1 how likely two names are spelled for the same person, comparing their normalized forms
+// regardless of the order of the words. A given name abbreviated to its first letters, like "M. Ødegaard", scores 0.9,
+// and a single name found among the words of the other, like "Salah" and "Mohamed Salah", 0.8.
+func Similarity(a string, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	score := max(editSimilarity(a, b), editSimilarity(sortedWords(wordsA), sortedWords(wordsB)))
+	if abbreviated(Parse(a), Parse(b)) {
+		score = max(score, 0.9)
+	}
+	if len(wordsA) == 1 && slices.Contains(wordsB, a) || len(wordsB) == 1 && slices.Contains(wordsA, b) {
+		score = max(score, 0.8)
+	}
+	return score
+}
+
+// abbreviated tells if two names share their family name, one of their given names being the start of the other.
+func abbreviated(a Name, b Name) bool {
+	if a.Family == "" || a.Family != b.Family {
+		return false
+	}
+	return strings.HasPrefix(a.Given, b.Given) || strings.HasPrefix(b.Given, a.Given)
+}
+
+// editSimilarity is 1 minus the edit distance between two strings relative to the longest one.
+func editSimilarity(a string, b string) float64 {
+	runesA, runesB := []rune(a), []rune(b)
+	longest := max(len(runesA), len(runesB))
+	if longest == 0 {
+		return 1
+	}
+	// Levenshtein distance, keeping only the previous row of the matrix
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(runesB)])/float64(longest)
+}
+
+// sortedWords joins the words of a name in alphabetical order.
+func sortedWords(words []string) string {
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+	return strings.Join(sorted, " ")
+}
@@ -0,0 +1,80 @@
+package names_test
+
+import (
+	"scoreplay/internal/names"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		fullName string
+		want     names.Name
+	}{
+		{fullName: "Virgil van Dijk", want: names.Name{Given: "Virgil", Family: "van Dijk", Display: "Virgil van Dijk"}},
+		{fullName: "Pepe", want: names.Name{Given: "Pepe", Display: "Pepe", KnownAs: "Pepe"}},
+		{fullName: "Luis Fernando Díaz", want: names.Name{Given: "Luis Fernando", Family: "Díaz", Display: "Luis Fernando Díaz"}},
+		{fullName: "Trent Alexander-Arnold", want: names.Name{Given: "Trent", Family: "Alexander-Arnold",
+			Display: "Trent Alexander-Arnold"}},
+		{fullName: "  Martin   Ødegaard ", want: names.Name{Given: "Martin", Family: "Ødegaard", Display: "Martin Ødegaard"}},
+		{fullName: "孫興慜", want: names.Name{Display: "孫興慜", Native: "孫興慜"}},
+		{fullName: "손 흥민", want: names.Name{Display: "손 흥민", Native: "손 흥민"}},
+		{fullName: "", want: names.Name{}},
+	}
+	for _, test := range tests {
+		t.Run(test.fullName, func(t *testing.T) {
+			if got := names.Parse(test.fullName); got != test.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.fullName, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		same bool
+	}{
+		{a: "Ødegaard", b: "Odegaard", same: true},
+		{a: "ØDEGAARD", b: "ødegaard", same: true},
+		{a: "Alexander-Arnold", b: "Alexander Arnold", same: true},
+		{a: "Raphaël Varane", b: "raphael  varane", same: true},
+		{a: "N'Golo Kanté", b: "NGolo Kante", same: true},
+		{a: "Müller", b: "Muller", same: true},
+		{a: "Saka", b: "Saliba", same: false},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" and "+test.b, func(t *testing.T) {
+			a, b := names.Normalize(test.a), names.Normalize(test.b)
+			if (a == b) != test.same {
+				t.Errorf("Normalize = %q and %q, want the same key: %t", a, b, test.same)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a   string
+		b   string
+		min float64
+		max float64
+	}{
+		{a: "Martin Ødegaard", b: "martin odegaard", min: 1, max: 1},
+		{a: "Trent Alexander-Arnold", b: "Trent Alexander Arnold", min: 1, max: 1},
+		{a: "Son Heung-min", b: "Heung-min Son", min: 1, max: 1},
+		{a: "M. Ødegaard", b: "Martin Odegaard", min: 0.9, max: 0.9},
+		{a: "Salah", b: "Mohamed Salah", min: 0.8, max: 0.8},
+		{a: "Gabriel Jesus", b: "Gabriel Jesús", min: 1, max: 1},
+		{a: "Gabriel Jesus", b: "Gabriel Martinelli", min: 0, max: 0.7},
+		{a: "Bukayo Saka", b: "William Saliba", min: 0, max: 0.5},
+		{a: "Pepe", b: "", min: 0, max: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" and "+test.b, func(t *testing.T) {
+			if got := names.Similarity(test.a, test.b); got < test.min || got > test.max {
+				t.Errorf("Similarity = %.2f, want between %.2f and %.2f", got, test.min, test.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"encoding/json"
+	"reflect"
+	"scoreplay/internal/entity"
+	"sort"
+	"time"
+)
+
+const (
+	HistoryEntityTeam   = "team"
+	HistoryEntityPlayer = "player"
+
+	HistoryActionCreate  = "create"
+	HistoryActionUpdate  = "update"
+	HistoryActionDelete  = "delete"
+	HistoryActionRestore = "restore"
+	HistoryActionPurge   = "purge"
+	HistoryActionMerge   = "merge"
+	HistoryActionSync    = "sync"
+
+	HistorySourceAPI  = "api"
+	HistorySourceSync = "sync"
+)
+
+// Auditor identifies who changes the repositories, and from where, in the history entries.
+type Auditor struct {
+	Actor  string
+	Source string
+}
+
+// NewAuditedTransaction wraps a transaction so every change made through it is recorded in its history
+// on behalf of the auditor.
+func NewAuditedTransaction(tx Transaction, auditor Auditor) Transaction {
+	return &auditedTransaction{
+		Transaction: tx,
+		auditor:     auditor,
+	}
+}
+
+type auditedTransaction struct {
+	Transaction
+	auditor Auditor
+}
+
+func (tx *auditedTransaction) Team() TeamRepository {
+	return &auditedTeamRepository{TeamRepository: tx.Transaction.Team(), history: tx.History()}
+}
+
+func (tx *auditedTransaction) Player() PlayerRepository {
+	return &auditedPlayerRepository{PlayerRepository: tx.Transaction.Player(), history: tx.History()}
+}
+
+// History stamps the entries created through it with the auditor and the current time.
+func (tx *auditedTransaction) History() HistoryRepository {
+	return &auditedHistoryRepository{HistoryRepository: tx.Transaction.History(), auditor: tx.auditor}
+}
+
+type auditedHistoryRepository struct {
+	HistoryRepository
+	auditor Auditor
+}
+
+func (repo *auditedHistoryRepository) Create(entry entity.HistoryEntry) (*entity.HistoryEntry, error) {
+	entry.Actor = repo.auditor.Actor
+	entry.Source = repo.auditor.Source
+	entry.Timestamp = time.Now().UTC()
+	return repo.HistoryRepository.Create(entry)
+}
+
+type auditedTeamRepository struct {
+	TeamRepository
+	history HistoryRepository
+}
+
+func (repo *auditedTeamRepository) Create(team entity.Team) (*entity.Team, error) {
+	teamCreated, err := repo.TeamRepository.Create(team)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(repo.history, HistoryEntityTeam, teamCreated.ID, HistoryActionCreate, nil, teamCreated); err != nil {
+		return nil, err
+	}
+	return teamCreated, nil
+}
+
+func (repo *auditedTeamRepository) Update(team entity.Team) (*entity.Team, error) {
+	previous, err := repo.TeamRepository.GetByID(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	teamUpdated, err := repo.TeamRepository.Update(team)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(repo.history, HistoryEntityTeam, team.ID, HistoryActionUpdate, previous, teamUpdated); err != nil {
+		return nil, err
+	}
+	return teamUpdated, nil
+}
+
+func (repo *auditedTeamRepository) Delete(id int) error {
+	previous, err := repo.TeamRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := repo.TeamRepository.Delete(id); err != nil {
+		return err
+	}
+	return recordChange(repo.history, HistoryEntityTeam, id, HistoryActionDelete, previous, nil)
+}
+
+func (repo *auditedTeamRepository) Restore(id int) (*entity.Team, error) {
+	teamRestored, err := repo.TeamRepository.Restore(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(repo.history, HistoryEntityTeam, id, HistoryActionRestore, nil, teamRestored); err != nil {
+		return nil, err
+	}
+	return teamRestored, nil
+}
+
+func (repo *auditedTeamRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	ids, err := repo.TeamRepository.Purge(deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := recordChange(repo.history, HistoryEntityTeam, id, HistoryActionPurge, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+type auditedPlayerRepository struct {
+	PlayerRepository
+	history HistoryRepository
+}
+
+func (repo *auditedPlayerRepository) Create(player entity.Player) (*entity.Player, error) {
+	playerCreated, err := repo.PlayerRepository.Create(player)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(repo.history, HistoryEntityPlayer, playerCreated.ID, HistoryActionCreate, nil, playerCreated); err != nil {
+		return nil, err
+	}
+	return playerCreated, nil
+}
+
+func (repo *auditedPlayerRepository) Update(player entity.Player) (*entity.Player, error) {
+	previous, err := repo.PlayerRepository.GetByID(player.ID)
+	if err != nil {
+		return nil, err
+	}
+	playerUpdated, err := repo.PlayerRepository.Update(player)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(repo.history, HistoryEntityPlayer, player.ID, HistoryActionUpdate, previous, playerUpdated); err != nil {
+		return nil, err
+	}
+	return playerUpdated, nil
+}
+
+func (repo *auditedPlayerRepository) Delete(id int) error {
+	previous, err := repo.PlayerRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := repo.PlayerRepository.Delete(id); err != nil {
+		return err
+	}
+	return recordChange(repo.history, HistoryEntityPlayer, id, HistoryActionDelete, previous, nil)
+}
+
+// Merge records the player the merged one was merged into.
+func (repo *auditedPlayerRepository) Merge(id int, intoID int) error {
+	previous, err := repo.PlayerRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := repo.PlayerRepository.Merge(id, intoID); err != nil {
+		return err
+	}
+	merged := *previous
+	merged.MergedInto = intoID
+	return recordChange(repo.history, HistoryEntityPlayer, id, HistoryActionMerge, previous, &merged)
+}
+
+func (repo *auditedPlayerRepository) Restore(id int) (*entity.Player, error) {
+	playerRestored, err := repo.PlayerRepository.Restore(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordChange(repo.history, HistoryEntityPlayer, id, HistoryActionRestore, nil, playerRestored); err != nil {
+		return nil, err
+	}
+	return playerRestored, nil
+}
+
+func (repo *auditedPlayerRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	ids, err := repo.PlayerRepository.Purge(deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := recordChange(repo.history, HistoryEntityPlayer, id, HistoryActionPurge, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// recordChange creates a history entry with the field-level difference between two versions of an entity,
+// before is nil for a creation or a restoration and after is nil for a deletion.
+func recordChange(history HistoryRepository, entityType string, entityID int, action string, before any, after any) error {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		return err
+	}
+	_, err = history.Create(entity.HistoryEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+	})
+	return err
+}
+
+// diffFields compares the JSON fields of two versions of an entity, except the identifier, the version, the deletion
+// time and the outcome of the last sync that are bookkeeping. Comparing the JSON values keeps the history identical
+// whatever the storage.
+func diffFields(before any, after any) ([]entity.FieldChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{})
+	for name := range beforeFields {
+		names[name] = struct{}{}
+	}
+	for name := range afterFields {
+		names[name] = struct{}{}
+	}
+	delete(names, "id")
+	delete(names, "version")
+	delete(names, "deleted_at")
+	delete(names, "last_synced_at")
+	delete(names, "last_sync_status")
+
+	changes := []entity.FieldChange{}
+	for name := range names {
+		beforeValue, afterValue := beforeFields[name], afterFields[name]
+		if before != nil && after != nil && sameValue(beforeValue, afterValue) {
+			continue
+		}
+		changes = append(changes, entity.FieldChange{Field: name, Before: beforeValue, After: afterValue})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+func jsonFields(value any) (map[string]any, error) {
+	if value == nil || reflect.ValueOf(value).IsNil() {
+		return nil, nil
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// sameValue compares two JSON values, considering a null list equal to an empty one.
+func sameValue(a any, b any) bool {
+	if list, ok := a.([]any); ok && len(list) == 0 {
+		a = nil
+	}
+	if list, ok := b.([]any); ok && len(list) == 0 {
+		b = nil
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"scoreplay/internal/entity"
+	"sync"
+)
+
+type InMemoryHistoryRepository struct {
+	mu      sync.RWMutex
+	entries []entity.HistoryEntry
+	journal *InMemoryJournal
+}
+
+func NewInMemoryHistoryRepository() *InMemoryHistoryRepository {
+	return &InMemoryHistoryRepository{}
+}
+
+func (repo *InMemoryHistoryRepository) Create(entry entity.HistoryEntry) (*entity.HistoryEntry, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.create(entry, repo)
+}
+
+func (repo *InMemoryHistoryRepository) GetByEntity(entityType string, entityID int, offset int, limit int) ([]entity.HistoryEntry, int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getByEntity(entityType, entityID, offset, limit)
+}
+
+func (repo *InMemoryHistoryRepository) create(entry entity.HistoryEntry, recorder changeRecorder) (*entity.HistoryEntry, error) {
+	entry.ID = len(repo.entries) + 1
+	undo := func() { repo.entries = repo.entries[:entry.ID-1] }
+	if err := recorder.record(journalRecord{Op: opPutHistory, History: &entry}, undo); err != nil {
+		return nil, err
+	}
+	repo.entries = append(repo.entries, entry)
+	return &entry, nil
+}
+
+func (repo *InMemoryHistoryRepository) getByEntity(entityType string, entityID int, offset int, limit int) ([]entity.HistoryEntry, int, error) {
+	var entries []entity.HistoryEntry
+	total := 0
+	for i := len(repo.entries) - 1; i >= 0; i-- {
+		entry := repo.entries[i]
+		if entry.EntityType != entityType || entry.EntityID != entityID {
+			continue
+		}
+		if total >= offset && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+		total++
+	}
+	return entries, total, nil
+}
+
+// record writes the change to the journal, if any, before it is applied.
+func (repo *InMemoryHistoryRepository) record(record journalRecord, _ func()) error {
+	if repo.journal == nil {
+		return nil
+	}
+	return repo.journal.append(record)
+}
@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"scoreplay/internal/entity"
+	"sync"
+	"time"
+)
+
+const (
+	journalFileName  = "journal.log"
+	snapshotFileName = "snapshot.json"
+	maxFrameSize     = 64 << 20
+
+	opPutTeam      = "put_team"
+	opDeleteTeam   = "delete_team"
+	opPutPlayer    = "put_player"
+	opDeletePlayer = "delete_player"
+	opPutHistory   = "put_history"
+)
+
+// journalRecord is a single change applied to the in-memory repositories.
+type journalRecord struct {
+	Op      string               `json:"op"`
+	ID      int                  `json:"id,omitempty"`
+	Team    *entity.Team         `json:"team,omitempty"`
+	Player  *entity.Player       `json:"player,omitempty"`
+	History *entity.HistoryEntry `json:"history,omitempty"`
+}
+
+type journalSnapshot struct {
+	TeamAutoID   int                   `json:"team_auto_id"`
+	PlayerAutoID int                   `json:"player_auto_id"`
+	Teams        []entity.Team         `json:"teams"`
+	Players      []entity.Player       `json:"players"`
+	History      []entity.HistoryEntry `json:"history"`
+}
+
+// InMemoryJournal persists the changes of the in-memory repositories in an append-only file.
+// Every frame of the journal is a length, a CRC32 checksum and a JSON list of records, and a
+// snapshot of the repositories is written periodically so the journal can be truncated.
+type InMemoryJournal struct {
+	mu      sync.Mutex
+	dir     string
+	file    *os.File
+	teams   *InMemoryTeamRepository
+	players *InMemoryPlayerRepository
+	history *InMemoryHistoryRepository
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// OpenInMemoryJournal rebuilds the in-memory repositories from the snapshot and journal stored in dir,
+// and compacts the journal every compactInterval when it is positive.
+func OpenInMemoryJournal(dir string, compactInterval time.Duration) (*InMemoryJournal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	j := &InMemoryJournal{
+		dir:     dir,
+		teams:   NewInMemoryTeamRepository(),
+		players: NewInMemoryPlayerRepository(),
+		history: NewInMemoryHistoryRepository(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := j.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := j.replay(); err != nil {
+		return nil, err
+	}
+	j.teams.journal = j
+	j.players.journal = j
+	j.history.journal = j
+
+	if compactInterval > 0 {
+		go j.compactPeriodically(compactInterval)
+	} else {
+		close(j.done)
+	}
+	return j, nil
+}
+
+func (j *InMemoryJournal) Teams() *InMemoryTeamRepository {
+	return j.teams
+}
+
+func (j *InMemoryJournal) Players() *InMemoryPlayerRepository {
+	return j.players
+}
+
+func (j *InMemoryJournal) History() *InMemoryHistoryRepository {
+	return j.history
+}
+
+// Repository returns the journaled repositories with a transaction manager spanning all of them.
+func (j *InMemoryJournal) Repository() Repository {
+	return newInMemoryRepository(j.teams, j.players, j.history)
+}
+
+// Compact writes a snapshot of the repositories and truncates the journal.
+func (j *InMemoryJournal) Compact() error {
+	// Block every writer so the snapshot and the journal stay consistent
+	j.teams.mu.Lock()
+	defer j.teams.mu.Unlock()
+	j.players.mu.Lock()
+	defer j.players.mu.Unlock()
+	j.history.mu.Lock()
+	defer j.history.mu.Unlock()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+
+	snapshot := journalSnapshot{
+		TeamAutoID:   j.teams.autoID,
+		PlayerAutoID: j.players.autoID,
+		History:      j.history.entries,
+	}
+	for _, team := range j.teams.teams {
+		snapshot.Teams = append(snapshot.Teams, team)
+	}
+	for _, player := range j.players.players {
+		snapshot.Players = append(snapshot.Players, player)
+	}
+	if err := j.writeSnapshot(snapshot); err != nil {
+		return err
+	}
+
+	// Replaying records already contained in the snapshot is harmless, so a crash before
+	// the truncation below only costs some replay time on the next start
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close stops the periodic compaction, writes a final snapshot and closes the journal file.
+func (j *InMemoryJournal) Close() error {
+	select {
+	case <-j.stop:
+		return nil
+	default:
+		close(j.stop)
+	}
+	<-j.done
+
+	err := j.Compact()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if closeErr := j.file.Close(); err == nil {
+		err = closeErr
+	}
+	j.file = nil
+	return err
+}
+
+// append durably writes the records as a single frame before they are applied in memory.
+func (j *InMemoryJournal) append(records ...journalRecord) error {
+	payload, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 8+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[8:], payload)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+	if _, err := j.file.Write(frame); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *InMemoryJournal) compactPeriodically(interval time.Duration) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Compact(); err != nil {
+				log.Printf("Error compacting journal: %v", err)
+			}
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+func (j *InMemoryJournal) loadSnapshot() error {
+	content, err := os.ReadFile(filepath.Join(j.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshot journalSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	j.teams.autoID = snapshot.TeamAutoID
+	for _, team := range snapshot.Teams {
+		j.teams.teams[team.ID] = team
+	}
+	j.players.autoID = snapshot.PlayerAutoID
+	for _, player := range snapshot.Players {
+		j.players.players[player.ID] = player
+	}
+	j.history.entries = snapshot.History
+	return nil
+}
+
+func (j *InMemoryJournal) writeSnapshot(snapshot journalSnapshot) error {
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial snapshot behind
+	tmpPath := filepath.Join(j.dir, snapshotFileName+".tmp")
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(j.dir, snapshotFileName))
+}
+
+// replay applies every complete frame of the journal and truncates a torn or corrupted tail,
+// leaving the file open for appending.
+func (j *InMemoryJournal) replay() error {
+	file, err := os.OpenFile(filepath.Join(j.dir, journalFileName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		records, size, err := readFrame(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Discarding torn journal record at offset %d: %v", offset, err)
+			break
+		}
+		for _, record := range records {
+			j.apply(record)
+		}
+		offset += size
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+	j.file = file
+	return nil
+}
+
+func readFrame(reader io.Reader) ([]journalRecord, int64, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, 0, err
+	}
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxFrameSize {
+		return nil, 0, fmt.Errorf("frame size %d exceeds the maximum", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("checksum mismatch")
+	}
+
+	var records []journalRecord
+	if err := json.Unmarshal(payload, &records); err != nil {
+		return nil, 0, err
+	}
+	return records, int64(len(header) + len(payload)), nil
+}
+
+// apply replays a record, records are idempotent so replaying one already in the snapshot is safe.
+func (j *InMemoryJournal) apply(record journalRecord) {
+	switch record.Op {
+	case opPutTeam:
+		j.teams.teams[record.Team.ID] = *record.Team
+		j.teams.autoID = max(j.teams.autoID, record.Team.ID)
+	case opDeleteTeam:
+		delete(j.teams.teams, record.ID)
+		j.teams.autoID = max(j.teams.autoID, record.ID)
+	case opPutPlayer:
+		j.players.players[record.Player.ID] = *record.Player
+		j.players.autoID = max(j.players.autoID, record.Player.ID)
+	case opDeletePlayer:
+		delete(j.players.players, record.ID)
+		j.players.autoID = max(j.players.autoID, record.ID)
+	case opPutHistory:
+		if record.History.ID > len(j.history.entries) {
+			j.history.entries = append(j.history.entries, *record.History)
+		}
+	}
+}
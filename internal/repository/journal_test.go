@@ -0,0 +1,197 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/repository"
+	"testing"
+)
+
+func TestInMemoryJournalReplaysTheSnapshotAndTheJournal(t *testing.T) {
+	dir := t.TempDir()
+	journal := openJournal(t, dir)
+	repo := journal.Repository()
+	saka := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	havertz := mustCreatePlayer(t, repo, "Kai", "Havertz")
+	team := mustCreateTeam(t, repo, "Arsenal", saka.ID, havertz.ID)
+	if err := journal.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	// Changed after the snapshot, so only in the journal
+	rice := mustCreatePlayer(t, repo, "Declan", "Rice")
+	team.Players = []int{rice.ID, saka.ID}
+	if _, err := repo.Team.Update(*team); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.Player.Delete(havertz.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// The journal is not closed, like after a crash
+	reopened := openJournal(t, dir).Repository()
+
+	found, err := reopened.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertIDs(t, found.Players, []int{rice.ID, saka.ID})
+	players, err := reopened.Player.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertIDs(t, sortedIDs(playerIDs(players)), []int{saka.ID, rice.ID})
+	_, err = reopened.Player.GetByID(havertz.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+}
+
+func TestInMemoryJournalCompacts(t *testing.T) {
+	dir := t.TempDir()
+	journal := openJournal(t, dir)
+	repo := journal.Repository()
+	saka := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	mustCreateTeam(t, repo, "Arsenal", saka.ID)
+	if size := fileSize(t, filepath.Join(dir, "journal.log")); size == 0 {
+		t.Fatalf("journal size = 0 before the compaction, want the records")
+	}
+
+	if err := journal.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if size := fileSize(t, filepath.Join(dir, "journal.log")); size != 0 {
+		t.Errorf("journal size = %d after the compaction, want 0", size)
+	}
+	if size := fileSize(t, filepath.Join(dir, "snapshot.json")); size == 0 {
+		t.Errorf("snapshot size = 0, want the teams and players")
+	}
+	// Closing compacts too, the reopened store is rebuilt from the snapshot alone
+	mustCreatePlayer(t, repo, "Kai", "Havertz")
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if size := fileSize(t, filepath.Join(dir, "journal.log")); size != 0 {
+		t.Errorf("journal size = %d after closing, want 0", size)
+	}
+
+	reopened := openJournal(t, dir).Repository()
+	players, err := reopened.Player.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertIDs(t, sortedIDs(playerIDs(players)), []int{1, 2})
+	found, err := reopened.Team.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertIDs(t, found.Players, []int{saka.ID})
+}
+
+func TestInMemoryJournalDropsADamagedLastRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(content []byte) []byte
+	}{
+		{name: "torn", damage: func(content []byte) []byte { return content[:len(content)-3] }},
+		{name: "torn header", damage: func(content []byte) []byte { return append(content, 0, 0, 1) }},
+		{name: "checksum mismatch", damage: func(content []byte) []byte {
+			content[len(content)-2] ^= 0xff
+			return content
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo := openJournal(t, dir).Repository()
+			for _, lastName := range []string{"Saka", "Havertz", "Rice"} {
+				mustCreatePlayer(t, repo, "Player", lastName)
+			}
+			path := filepath.Join(dir, "journal.log")
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if err := os.WriteFile(path, test.damage(content), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			reopened := openJournal(t, dir).Repository()
+			players, err := reopened.Player.GetAll()
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			wantIDs := []int{1, 2}
+			if test.name == "torn header" {
+				// The damage follows the last record, which is kept
+				wantIDs = []int{1, 2, 3}
+			}
+			assertIDs(t, sortedIDs(playerIDs(players)), wantIDs)
+
+			// The damaged tail is truncated, so the records appended next are replayed too
+			mustCreatePlayer(t, reopened, "Player", "Saliba")
+			replayed := openJournal(t, dir).Repository()
+			players, err = replayed.Player.GetAll()
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if len(players) != len(wantIDs)+1 {
+				t.Errorf("players = %v after a new record, want %d", playerIDs(players), len(wantIDs)+1)
+			}
+		})
+	}
+}
+
+func TestInMemoryJournalKeepsTheIDsAfterReplay(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		name := "journal"
+		if compact {
+			name = "snapshot"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			journal := openJournal(t, dir)
+			repo := journal.Repository()
+			mustCreatePlayer(t, repo, "Bukayo", "Saka")
+			last := mustCreatePlayer(t, repo, "Kai", "Havertz")
+			team := mustCreateTeam(t, repo, "Arsenal")
+			if err := repo.Player.Delete(last.ID); err != nil {
+				t.Fatalf("Delete player: %v", err)
+			}
+			if err := repo.Team.Delete(team.ID); err != nil {
+				t.Fatalf("Delete team: %v", err)
+			}
+			if compact {
+				if err := journal.Compact(); err != nil {
+					t.Fatalf("Compact: %v", err)
+				}
+			}
+
+			// The IDs of the deleted entities are not given again
+			reopened := openJournal(t, dir).Repository()
+			if player := mustCreatePlayer(t, reopened, "Declan", "Rice"); player.ID != last.ID+1 {
+				t.Errorf("player ID = %d, want %d", player.ID, last.ID+1)
+			}
+			if created := mustCreateTeam(t, reopened, "Chelsea"); created.ID != team.ID+1 {
+				t.Errorf("team ID = %d, want %d", created.ID, team.ID+1)
+			}
+		})
+	}
+}
+
+// openJournal opens the journal stored in dir, without compacting it periodically.
+func openJournal(t *testing.T, dir string) *repository.InMemoryJournal {
+	t.Helper()
+	journal, err := repository.OpenInMemoryJournal(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenInMemoryJournal: %v", err)
+	}
+	return journal
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	return info.Size()
+}
@@ -1,13 +1,23 @@
 package repository
 
 import (
+	"maps"
 	"scoreplay/internal/entity"
 	"scoreplay/internal/exception"
+	"scoreplay/internal/names"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type InMemoryPlayerRepository struct {
+	mu      sync.RWMutex
 	players map[int]entity.Player
 	autoID  int
+	journal *InMemoryJournal
+	teams   *InMemoryTeamRepository
 }
 
 func NewInMemoryPlayerRepository() *InMemoryPlayerRepository {
@@ -18,42 +28,284 @@ func NewInMemoryPlayerRepository() *InMemoryPlayerRepository {
 }
 
 func (repo *InMemoryPlayerRepository) GetAll() ([]entity.Player, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getAll()
+}
+
+func (repo *InMemoryPlayerRepository) Find(query PlayerQuery) (*PlayerPage, error) {
+	// Lock the linked teams first, like transactions do, to read the roster filtered on
+	if repo.teams != nil && query.Filter.TeamID != 0 {
+		repo.teams.mu.RLock()
+		defer repo.teams.mu.RUnlock()
+	}
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.find(query)
+}
+
+func (repo *InMemoryPlayerRepository) GetByID(id int) (*entity.Player, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getByID(id)
+}
+
+func (repo *InMemoryPlayerRepository) Create(player entity.Player) (*entity.Player, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.create(player, repo)
+}
+
+func (repo *InMemoryPlayerRepository) Update(player entity.Player) (*entity.Player, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.update(player, repo)
+}
+
+func (repo *InMemoryPlayerRepository) Delete(id int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.delete(id, repo)
+}
+
+func (repo *InMemoryPlayerRepository) Merge(id int, intoID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.merge(id, intoID, repo)
+}
+
+func (repo *InMemoryPlayerRepository) GetMergedInto(id int) (int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getMergedInto(id)
+}
+
+func (repo *InMemoryPlayerRepository) GetDeleted() ([]entity.Player, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getDeleted()
+}
+
+func (repo *InMemoryPlayerRepository) Restore(id int) (*entity.Player, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.restore(id, repo)
+}
+
+func (repo *InMemoryPlayerRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.purge(deletedBefore, repo)
+}
+
+func (repo *InMemoryPlayerRepository) getAll() ([]entity.Player, error) {
 	var players []entity.Player
 	for _, player := range repo.players {
-		players = append(players, player)
+		if player.DeletedAt == nil {
+			players = append(players, clonePlayer(player))
+		}
 	}
 	return players, nil
 }
 
-func (repo *InMemoryPlayerRepository) GetByID(id int) (*entity.Player, error) {
+func (repo *InMemoryPlayerRepository) find(query PlayerQuery) (*PlayerPage, error) {
+	var roster []int
+	if query.Filter.TeamID != 0 && repo.teams != nil {
+		if team, exists := repo.teams.teams[query.Filter.TeamID]; exists && team.DeletedAt == nil {
+			roster = team.Players
+		}
+	}
+
+	players := []entity.Player{}
+	for _, player := range repo.players {
+		if player.DeletedAt != nil || !playerMatches(player, query.Filter) {
+			continue
+		}
+		if query.Filter.TeamID != 0 && !slices.Contains(roster, player.ID) {
+			continue
+		}
+		players = append(players, clonePlayer(player))
+	}
+	selected, nextCursor, err := paginate(players, query.Sort, query.Page, playerSortableFields)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerPage{Players: selected, Total: len(players), NextCursor: nextCursor}, nil
+}
+
+func (repo *InMemoryPlayerRepository) getByID(id int) (*entity.Player, error) {
 	player, exists := repo.players[id]
-	if !exists {
+	if !exists || player.DeletedAt != nil {
 		return nil, exception.EntityNotFoundException{Entity: "Player", ID: id}
 	}
+	player = clonePlayer(player)
 	return &player, nil
 }
 
-func (repo *InMemoryPlayerRepository) Create(player entity.Player) (*entity.Player, error) {
+func (repo *InMemoryPlayerRepository) create(player entity.Player, recorder changeRecorder) (*entity.Player, error) {
+	player.ID = repo.autoID + 1
+	player.Version = 1
+	stored := clonePlayer(player)
+	undo := func() { delete(repo.players, player.ID) }
+	if err := recorder.record(journalRecord{Op: opPutPlayer, Player: &stored}, undo); err != nil {
+		return nil, err
+	}
 	repo.autoID++
-	player.ID = repo.autoID
-	repo.players[player.ID] = player
+	repo.players[player.ID] = stored
 	return &player, nil
 }
 
-func (repo *InMemoryPlayerRepository) Update(player entity.Player) (*entity.Player, error) {
-	_, exists := repo.players[player.ID]
-	if !exists {
+func (repo *InMemoryPlayerRepository) update(player entity.Player, recorder changeRecorder) (*entity.Player, error) {
+	previous, exists := repo.players[player.ID]
+	if !exists || previous.DeletedAt != nil {
 		return nil, exception.EntityNotFoundException{Entity: "Player", ID: player.ID}
 	}
-	repo.players[player.ID] = player
+	if previous.Version != player.Version {
+		return nil, exception.VersionConflictException{Entity: "Player", ID: player.ID, ExpectedVersion: player.Version, ActualVersion: previous.Version}
+	}
+	player.Version++
+	stored := clonePlayer(player)
+	undo := func() { repo.players[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutPlayer, Player: &stored}, undo); err != nil {
+		return nil, err
+	}
+	repo.players[player.ID] = stored
 	return &player, nil
 }
 
-func (repo *InMemoryPlayerRepository) Delete(id int) error {
-	_, exists := repo.players[id]
-	if !exists {
+func (repo *InMemoryPlayerRepository) delete(id int, recorder changeRecorder) error {
+	previous, exists := repo.players[id]
+	if !exists || previous.DeletedAt != nil {
 		return exception.EntityNotFoundException{Entity: "Player", ID: id}
 	}
-	delete(repo.players, id)
+	player := previous
+	deletedAt := time.Now().UTC()
+	player.DeletedAt = &deletedAt
+	player.Version++
+	undo := func() { repo.players[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutPlayer, Player: &player}, undo); err != nil {
+		return err
+	}
+	repo.players[id] = player
 	return nil
 }
+
+func (repo *InMemoryPlayerRepository) merge(id int, intoID int, recorder changeRecorder) error {
+	previous, exists := repo.players[id]
+	if !exists || previous.DeletedAt != nil {
+		return exception.EntityNotFoundException{Entity: "Player", ID: id}
+	}
+	player := clonePlayer(previous)
+	deletedAt := time.Now().UTC()
+	player.DeletedAt = &deletedAt
+	player.MergedInto = intoID
+	player.Version++
+	undo := func() { repo.players[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutPlayer, Player: &player}, undo); err != nil {
+		return err
+	}
+	repo.players[id] = player
+	return nil
+}
+
+func (repo *InMemoryPlayerRepository) getMergedInto(id int) (int, error) {
+	player, exists := repo.players[id]
+	if !exists || player.MergedInto == 0 {
+		return 0, exception.EntityNotFoundException{Entity: "Player", ID: id}
+	}
+	return player.MergedInto, nil
+}
+
+func (repo *InMemoryPlayerRepository) getDeleted() ([]entity.Player, error) {
+	var players []entity.Player
+	for _, player := range repo.players {
+		if player.DeletedAt != nil && player.MergedInto == 0 {
+			players = append(players, clonePlayer(player))
+		}
+	}
+	return players, nil
+}
+
+func (repo *InMemoryPlayerRepository) restore(id int, recorder changeRecorder) (*entity.Player, error) {
+	previous, exists := repo.players[id]
+	if !exists || previous.DeletedAt == nil || previous.MergedInto != 0 {
+		return nil, exception.EntityNotFoundException{Entity: "Player", ID: id}
+	}
+	player := previous
+	player.DeletedAt = nil
+	player.Version++
+	undo := func() { repo.players[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutPlayer, Player: &player}, undo); err != nil {
+		return nil, err
+	}
+	repo.players[id] = player
+	player = clonePlayer(player)
+	return &player, nil
+}
+
+func (repo *InMemoryPlayerRepository) purge(deletedBefore time.Time, recorder changeRecorder) ([]int, error) {
+	var ids []int
+	for _, player := range repo.players {
+		if player.DeletedAt != nil && player.MergedInto == 0 && player.DeletedAt.Before(deletedBefore) {
+			ids = append(ids, player.ID)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		previous := repo.players[id]
+		undo := func() { repo.players[previous.ID] = previous }
+		if err := recorder.record(journalRecord{Op: opDeletePlayer, ID: id}, undo); err != nil {
+			return nil, err
+		}
+		delete(repo.players, id)
+	}
+	return ids, nil
+}
+
+// record writes the change to the journal, if any, before it is applied.
+func (repo *InMemoryPlayerRepository) record(record journalRecord, _ func()) error {
+	if repo.journal == nil {
+		return nil
+	}
+	return repo.journal.append(record)
+}
+
+// clonePlayer copies the external identifiers and the edited fields so callers never share the ones stored in the map.
+func clonePlayer(player entity.Player) entity.Player {
+	player.ExternalIDs = maps.Clone(player.ExternalIDs)
+	player.EditedFields = slices.Clone(player.EditedFields)
+	return player
+}
+
+func playerMatches(player entity.Player, filter PlayerFilter) bool {
+	if filter.Name != "" && !strings.Contains(playerSearchName(player), names.Normalize(filter.Name)) {
+		return false
+	}
+	if filter.LastName != "" && names.Normalize(player.LastName) != names.Normalize(filter.LastName) {
+		return false
+	}
+	return true
+}
+
+// playerSearchName returns the names of a player the name filter searches, normalized and separated by "|" so a search
+// does not match across two of them.
+func playerSearchName(player entity.Player) string {
+	searchNames := []string{names.Normalize(player.FirstName + " " + player.LastName)}
+	for _, name := range []string{player.DisplayName, player.KnownAs, player.NativeName} {
+		if name := names.Normalize(name); name != "" && !slices.Contains(searchNames, name) {
+			searchNames = append(searchNames, name)
+		}
+	}
+	return strings.Join(searchNames, "|")
+}
@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortField sorts the results of a query on a field, in ascending order unless Descending.
+type SortField struct {
+	Name       string
+	Descending bool
+}
+
+// ParseSort parses a comma separated list of field names, each descending when prefixed by "-".
+func ParseSort(fields string) []SortField {
+	var sortFields []SortField
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		descending := strings.HasPrefix(name, "-")
+		sortFields = append(sortFields, SortField{Name: strings.TrimPrefix(name, "-"), Descending: descending})
+	}
+	return sortFields
+}
+
+// Page selects the results of a query, from an offset or after the cursor of a previous page.
+// A cursor keeps the pages stable while entities are created or deleted, it takes precedence over the offset.
+type Page struct {
+	Offset int
+	Limit  int
+	Cursor string
+}
+
+type TeamFilter struct {
+	// Name keeps the teams whose name contains it, ignoring the case
+	Name string
+	// HasLogo keeps the teams with, or without, a logo
+	HasLogo *bool
+	// PlayerID keeps the teams with the player on their roster
+	PlayerID int
+}
+
+type TeamQuery struct {
+	Filter TeamFilter
+	Sort   []SortField
+	Page   Page
+}
+
+// TeamPage holds a page of teams, the number of teams matching the filter, and the cursor of the next page if any.
+type TeamPage struct {
+	Teams      []entity.Team
+	Total      int
+	NextCursor string
+}
+
+type PlayerFilter struct {
+	// Name keeps the players whose full name contains it, ignoring the case
+	Name string
+	// LastName keeps the players with this last name, ignoring the case
+	LastName string
+	// TeamID keeps the players on the roster of the team
+	TeamID int
+}
+
+type PlayerQuery struct {
+	Filter PlayerFilter
+	Sort   []SortField
+	Page   Page
+}
+
+// PlayerPage holds a page of players, the number of players matching the filter, and the cursor of the next page if any.
+type PlayerPage struct {
+	Players    []entity.Player
+	Total      int
+	NextCursor string
+}
+
+// sortableField is a field the results of a query can be sorted on, with its column and its value in an entity.
+type sortableField[T any] struct {
+	column string
+	value  func(T) any
+}
+
+var teamSortableFields = map[string]sortableField[entity.Team]{
+	"id":   {column: "id", value: func(team entity.Team) any { return team.ID }},
+	"name": {column: "name", value: func(team entity.Team) any { return team.Name }},
+}
+
+var playerSortableFields = map[string]sortableField[entity.Player]{
+	"id":         {column: "id", value: func(player entity.Player) any { return player.ID }},
+	"first_name": {column: "first_name", value: func(player entity.Player) any { return player.FirstName }},
+	"last_name":  {column: "last_name", value: func(player entity.Player) any { return player.LastName }},
+}
+
+// normalizeSort validates the sort fields and ends them with the ID, so the order of the results is total.
+func normalizeSort[T any](sortFields []SortField, fields map[string]sortableField[T]) ([]SortField, error) {
+	var normalized []SortField
+	seen := make(map[string]struct{})
+	for _, field := range sortFields {
+		if _, exists := fields[field.Name]; !exists {
+			return nil, exception.InvalidArgumentException{Argument: "sort", Reason: fmt.Sprintf("unknown field %q", field.Name)}
+		}
+		if _, duplicated := seen[field.Name]; duplicated {
+			continue
+		}
+		seen[field.Name] = struct{}{}
+		normalized = append(normalized, field)
+	}
+	if _, exists := seen["id"]; !exists {
+		normalized = append(normalized, SortField{Name: "id"})
+	}
+	return normalized, nil
+}
+
+// pageCursor is the position of the last entity of a page, it is only valid with the sort it was created with.
+type pageCursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func sortKey(sortFields []SortField) string {
+	var names []string
+	for _, field := range sortFields {
+		if field.Descending {
+			names = append(names, "-"+field.Name)
+		} else {
+			names = append(names, field.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func encodeCursor[T any](last T, sortFields []SortField, fields map[string]sortableField[T]) string {
+	cursor := pageCursor{Sort: sortKey(sortFields)}
+	for _, field := range sortFields {
+		cursor.Values = append(cursor.Values, fields[field.Name].value(last))
+	}
+	content, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// decodeCursor returns the sort values of the cursor, with the type of the fields they belong to.
+func decodeCursor[T any](encoded string, sortFields []SortField, fields map[string]sortableField[T]) ([]any, error) {
+	invalid := exception.InvalidArgumentException{Argument: "cursor", Reason: "malformed or created with another sort"}
+	content, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var cursor pageCursor
+	if err := decoder.Decode(&cursor); err != nil || cursor.Sort != sortKey(sortFields) || len(cursor.Values) != len(sortFields) {
+		return nil, invalid
+	}
+
+	var zero T
+	values := make([]any, len(sortFields))
+	for i, field := range sortFields {
+		switch fields[field.Name].value(zero).(type) {
+		case int:
+			number, ok := cursor.Values[i].(json.Number)
+			if !ok {
+				return nil, invalid
+			}
+			value, err := strconv.Atoi(number.String())
+			if err != nil {
+				return nil, invalid
+			}
+			values[i] = value
+		case string:
+			value, ok := cursor.Values[i].(string)
+			if !ok {
+				return nil, invalid
+			}
+			values[i] = value
+		}
+	}
+	return values, nil
+}
+
+// compareSortValues compares two lists of sort values, honoring the direction of every field.
+func compareSortValues(a []any, b []any, sortFields []SortField) int {
+	for i, field := range sortFields {
+		var result int
+		switch value := a[i].(type) {
+		case int:
+			result = compareOrdered(value, b[i].(int))
+		case string:
+			result = compareOrdered(value, b[i].(string))
+		}
+		if field.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func compareOrdered[V int | string](a V, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// paginate sorts the entities matching a query, already filtered, and selects the requested page in memory.
+func paginate[T any](entities []T, sortFields []SortField, page Page, fields map[string]sortableField[T]) ([]T, string, error) {
+	sortFields, err := normalizeSort(sortFields, fields)
+	if err != nil {
+		return nil, "", err
+	}
+	values := func(item T) []any {
+		var itemValues []any
+		for _, field := range sortFields {
+			itemValues = append(itemValues, fields[field.Name].value(item))
+		}
+		return itemValues
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		return compareSortValues(values(entities[i]), values(entities[j]), sortFields) < 0
+	})
+
+	start := min(page.Offset, len(entities))
+	if page.Cursor != "" {
+		after, err := decodeCursor(page.Cursor, sortFields, fields)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(entities), func(i int) bool {
+			return compareSortValues(values(entities[i]), after, sortFields) > 0
+		})
+	}
+	end := len(entities)
+	if page.Limit > 0 {
+		end = min(start+page.Limit, len(entities))
+	}
+
+	selected := entities[start:end]
+	nextCursor := ""
+	if end < len(entities) && len(selected) > 0 {
+		nextCursor = encodeCursor(selected[len(selected)-1], sortFields, fields)
+	}
+	return selected, nextCursor, nil
+}
+
+// sqlConditions accumulates the conditions of a WHERE clause, numbering the "?" placeholders of every condition.
+type sqlConditions struct {
+	conditions []string
+	args       []any
+}
+
+func (c *sqlConditions) add(condition string, args ...any) {
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		condition = strings.Replace(condition, "?", "$"+strconv.Itoa(len(c.args)), 1)
+	}
+	c.conditions = append(c.conditions, condition)
+}
+
+// addAfter keeps the rows sorted after the given sort values, a keyset condition an index can serve.
+func (c *sqlConditions) addAfter(sortFields []SortField, values []any, columns func(name string) string) {
+	var alternatives []string
+	var args []any
+	for i, field := range sortFields {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns(sortFields[j].Name)+" = ?")
+			args = append(args, values[j])
+		}
+		operator := " > ?"
+		if field.Descending {
+			operator = " < ?"
+		}
+		terms = append(terms, columns(field.Name)+operator)
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	c.add("("+strings.Join(alternatives, " OR ")+")", args...)
+}
+
+func (c *sqlConditions) where() string {
+	if len(c.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.conditions, " AND ")
+}
+
+// orderBy returns the ORDER BY clause of the sort fields.
+func orderBy(sortFields []SortField, columns func(name string) string) string {
+	var terms []string
+	for _, field := range sortFields {
+		if field.Descending {
+			terms = append(terms, columns(field.Name)+" DESC")
+		} else {
+			terms = append(terms, columns(field.Name))
+		}
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// likePattern matches the values containing the text, with the LIKE wildcards of the text escaped by a backslash.
+func likePattern(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(strings.ToLower(text)) + "%"
+}
+
+// findPage completes the conditions of a query with its page, returning the WHERE, ORDER BY and LIMIT clauses
+// and their arguments. One more row than the limit is selected to know whether a next page exists.
+func findPage[T any](conditions sqlConditions, sortFields []SortField, page Page, fields map[string]sortableField[T]) (string, []any, error) {
+	columns := func(name string) string { return fields[name].column }
+	offset := page.Offset
+	if page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor, sortFields, fields)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions.addAfter(sortFields, values, columns)
+		offset = 0
+	}
+	limit := math.MaxInt32
+	if page.Limit > 0 {
+		limit = page.Limit + 1
+	}
+	args := append(conditions.args, limit, offset)
+	clauses := conditions.where() + orderBy(sortFields, columns) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	return clauses, args, nil
+}
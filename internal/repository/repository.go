@@ -1,25 +1,93 @@
 package repository
 
-import "scoreplay/internal/entity"
+import (
+	"scoreplay/internal/entity"
+	"time"
+)
 
+// TeamRepository hides the soft-deleted teams from every method but GetDeleted, Restore and Purge.
 type TeamRepository interface {
 	GetAll() ([]entity.Team, error)
+	// Find returns the page of teams matching the query, sorted by ID unless specified.
+	Find(query TeamQuery) (*TeamPage, error)
 	GetByID(id int) (*entity.Team, error)
 	Create(team entity.Team) (*entity.Team, error)
 	Update(team entity.Team) (*entity.Team, error)
+	// Delete soft-deletes the team, keeping its roster until it is purged.
 	Delete(id int) error
 	GetPlayersByID(id int) ([]entity.Player, error)
+	GetByPlayerID(playerID int) ([]entity.Team, error)
+	GetDeleted() ([]entity.Team, error)
+	Restore(id int) (*entity.Team, error)
+	// Purge permanently removes the teams deleted before the given time and returns their IDs.
+	Purge(deletedBefore time.Time) ([]int, error)
+	// RecordSync records the time and the status of the last sync of the team, without changing its version.
+	RecordSync(id int, syncedAt time.Time, status string) error
 }
 
+// PlayerRepository hides the soft-deleted players from every method but GetDeleted, Restore and Purge.
 type PlayerRepository interface {
 	GetAll() ([]entity.Player, error)
+	// Find returns the page of players matching the query, sorted by ID unless specified.
+	Find(query PlayerQuery) (*PlayerPage, error)
 	GetByID(id int) (*entity.Player, error)
 	Create(player entity.Player) (*entity.Player, error)
 	Update(player entity.Player) (*entity.Player, error)
+	// Delete soft-deletes the player.
 	Delete(id int) error
+	// Merge soft-deletes a player merged into another one. A merged player is kept out of the trash, and never restored
+	// or purged, so its ID keeps leading to the other one.
+	Merge(id int, intoID int) error
+	// GetMergedInto returns the ID of the player a merged player was merged into.
+	GetMergedInto(id int) (int, error)
+	GetDeleted() ([]entity.Player, error)
+	Restore(id int) (*entity.Player, error)
+	// Purge permanently removes the players deleted before the given time and returns their IDs.
+	Purge(deletedBefore time.Time) ([]int, error)
+}
+
+// HistoryRepository stores the immutable history of the changes of teams and players.
+type HistoryRepository interface {
+	Create(entry entity.HistoryEntry) (*entity.HistoryEntry, error)
+	// GetByEntity returns a page of the history of an entity, most recent first, and the total number of entries.
+	GetByEntity(entityType string, entityID int, offset int, limit int) ([]entity.HistoryEntry, int, error)
+}
+
+// Transaction exposes repositories whose changes are applied all-or-nothing on Commit.
+// Repositories outside the transaction must not be used until it is committed or rolled back.
+type Transaction interface {
+	Team() TeamRepository
+	Player() PlayerRepository
+	History() HistoryRepository
+	Commit() error
+	Rollback() error
+}
+
+type TransactionManager interface {
+	Begin() (Transaction, error)
 }
 
 type Repository struct {
-	Team   TeamRepository
-	Player PlayerRepository
+	Team         TeamRepository
+	Player       PlayerRepository
+	History      HistoryRepository
+	Transactions TransactionManager
+}
+
+func (r Repository) Begin() (Transaction, error) {
+	return r.Transactions.Begin()
+}
+
+// InTransaction runs fn in a transaction, committing it when fn succeeds and rolling it back otherwise.
+func (r Repository) InTransaction(fn func(tx Transaction) error) error {
+	tx, err := r.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
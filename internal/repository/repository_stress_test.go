@@ -0,0 +1,280 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/repository"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	stressWorkers    = 4
+	stressIterations = 150
+	stressTimeout    = 30 * time.Second
+)
+
+// TestInMemoryRepositoryStress runs creates, updates, deletes and transactions on the in-memory repositories from
+// many goroutines, to be run with -race. The repositories lock the teams, then the players, then the history, so
+// nothing deadlocks, and the rosters only ever reference live players.
+func TestInMemoryRepositoryStress(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		repo := repository.NewInMemoryRepository()
+		runStress(t, repo)
+		checkRosters(t, repo)
+	})
+
+	t.Run("journal", func(t *testing.T) {
+		dir := t.TempDir()
+		// Compact while the workers run, the compaction taking the locks like a transaction
+		journal, err := repository.OpenInMemoryJournal(dir, 5*time.Millisecond)
+		if err != nil {
+			t.Fatalf("OpenInMemoryJournal: %v", err)
+		}
+		repo := journal.Repository()
+		runStress(t, repo)
+		checkRosters(t, repo)
+		teams, players := snapshot(t, repo)
+		if err := journal.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		reopened, err := repository.OpenInMemoryJournal(dir, 0)
+		if err != nil {
+			t.Fatalf("OpenInMemoryJournal: %v", err)
+		}
+		defer reopened.Close()
+		reopenedTeams, reopenedPlayers := snapshot(t, reopened.Repository())
+		if fmt.Sprint(reopenedTeams) != fmt.Sprint(teams) || fmt.Sprint(reopenedPlayers) != fmt.Sprint(players) {
+			t.Errorf("reopened journal has teams %v and players %v, want %v and %v", reopenedTeams, reopenedPlayers, teams, players)
+		}
+	})
+}
+
+// runStress seeds the repositories and runs every kind of worker concurrently, failing on a deadlock.
+func runStress(t *testing.T, repo repository.Repository) {
+	t.Helper()
+	for i := 0; i < 5; i++ {
+		var roster []int
+		for j := 0; j < 4; j++ {
+			roster = append(roster, mustCreatePlayer(t, repo, "Player", fmt.Sprintf("%d-%d", i, j)).ID)
+		}
+		mustCreateTeam(t, repo, fmt.Sprintf("Team %d", i), roster...)
+	}
+
+	workers := []func(random *rand.Rand) error{
+		func(random *rand.Rand) error { return stressCreate(repo, random) },
+		func(random *rand.Rand) error { return stressUpdate(repo, random) },
+		func(random *rand.Rand) error { return stressDelete(repo, random) },
+		func(random *rand.Rand) error { return stressTransaction(repo, random) },
+		func(random *rand.Rand) error { return stressRead(repo, random) },
+	}
+	errs := make(chan error, len(workers)*stressWorkers)
+	var wg sync.WaitGroup
+	for i, worker := range workers {
+		for j := 0; j < stressWorkers; j++ {
+			wg.Add(1)
+			go func(random *rand.Rand) {
+				defer wg.Done()
+				for k := 0; k < stressIterations; k++ {
+					if err := worker(random); err != nil && !expectedStressError(err) {
+						errs <- err
+						return
+					}
+				}
+			}(rand.New(rand.NewSource(int64(i*stressWorkers + j))))
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(stressTimeout):
+		t.Fatalf("the workers did not finish within %s, deadlocked", stressTimeout)
+	}
+	close(errs)
+	for err := range errs {
+		t.Errorf("worker failed: %v", err)
+	}
+}
+
+// expectedStressError tells whether an error is the expected outcome of a race between the workers: a stale version,
+// an entity deleted meanwhile, a roster referencing a player deleted meanwhile, or a transaction rolled back on
+// purpose.
+func expectedStressError(err error) bool {
+	var conflict exception.VersionConflictException
+	var notFound exception.EntityNotFoundException
+	var integrity exception.ReferentialIntegrityException
+	return errors.As(err, &conflict) || errors.As(err, &notFound) || errors.As(err, &integrity) || errors.Is(err, errStressRollback)
+}
+
+var errStressRollback = errors.New("rolled back on purpose")
+
+// stressCreate creates a player and adds it to a random team.
+func stressCreate(repo repository.Repository, random *rand.Rand) error {
+	player, err := repo.Player.Create(entity.Player{FirstName: "Created", LastName: fmt.Sprint(random.Int())})
+	if err != nil {
+		return err
+	}
+	team, err := randomTeam(repo, random)
+	if err != nil || team == nil {
+		return err
+	}
+	team.Players = append(team.Players, player.ID)
+	_, err = repo.Team.Update(*team)
+	return err
+}
+
+// stressUpdate reorders the roster of a random team and renames one of its players.
+func stressUpdate(repo repository.Repository, random *rand.Rand) error {
+	team, err := randomTeam(repo, random)
+	if err != nil || team == nil || len(team.Players) == 0 {
+		return err
+	}
+	random.Shuffle(len(team.Players), func(i, j int) {
+		team.Players[i], team.Players[j] = team.Players[j], team.Players[i]
+	})
+	if _, err := repo.Team.Update(*team); err != nil {
+		return err
+	}
+	player, err := repo.Player.GetByID(team.Players[0])
+	if err != nil {
+		return err
+	}
+	player.FirstName = fmt.Sprint("Updated ", random.Int())
+	_, err = repo.Player.Update(*player)
+	return err
+}
+
+// stressDelete removes a random player from every roster and deletes it, all-or-nothing.
+func stressDelete(repo repository.Repository, random *rand.Rand) error {
+	players, err := repo.Player.GetAll()
+	if err != nil || len(players) < 10 {
+		return err
+	}
+	playerID := players[random.Intn(len(players))].ID
+	return repo.InTransaction(func(tx repository.Transaction) error {
+		teams, err := tx.Team().GetByPlayerID(playerID)
+		if err != nil {
+			return err
+		}
+		for _, team := range teams {
+			team.Players = slices.DeleteFunc(team.Players, func(id int) bool { return id == playerID })
+			if _, err := tx.Team().Update(team); err != nil {
+				return err
+			}
+		}
+		if err := tx.Player().Delete(playerID); err != nil {
+			return err
+		}
+		_, err = tx.History().Create(entity.HistoryEntry{EntityType: repository.HistoryEntityPlayer, EntityID: playerID,
+			Action: repository.HistoryActionDelete, Timestamp: time.Now().UTC()})
+		return err
+	})
+}
+
+// stressTransaction creates a player on a random team with its history, rolling back one transaction out of three.
+func stressTransaction(repo repository.Repository, random *rand.Rand) error {
+	return repo.InTransaction(func(tx repository.Transaction) error {
+		teams, err := tx.Team().GetAll()
+		if err != nil || len(teams) == 0 {
+			return err
+		}
+		team := teams[random.Intn(len(teams))]
+		player, err := tx.Player().Create(entity.Player{FirstName: "Transaction", LastName: fmt.Sprint(random.Int())})
+		if err != nil {
+			return err
+		}
+		team.Players = append(team.Players, player.ID)
+		if _, err := tx.Team().Update(team); err != nil {
+			return err
+		}
+		if _, err := tx.History().Create(entity.HistoryEntry{EntityType: repository.HistoryEntityTeam, EntityID: team.ID,
+			Action: repository.HistoryActionUpdate, Timestamp: time.Now().UTC()}); err != nil {
+			return err
+		}
+		if random.Intn(3) == 0 {
+			return errStressRollback
+		}
+		return nil
+	})
+}
+
+// stressRead reads the rosters, the pages and the history while the others write.
+func stressRead(repo repository.Repository, random *rand.Rand) error {
+	team, err := randomTeam(repo, random)
+	if err != nil || team == nil {
+		return err
+	}
+	if _, err := repo.Player.Find(repository.PlayerQuery{Filter: repository.PlayerFilter{TeamID: team.ID}}); err != nil {
+		return err
+	}
+	if _, err := repo.Team.Find(repository.TeamQuery{Sort: repository.ParseSort("-name"), Page: repository.Page{Limit: 2}}); err != nil {
+		return err
+	}
+	if _, err := repo.Team.GetPlayersByID(team.ID); err != nil {
+		return err
+	}
+	_, _, err = repo.History.GetByEntity(repository.HistoryEntityTeam, team.ID, 0, 5)
+	return err
+}
+
+func randomTeam(repo repository.Repository, random *rand.Rand) (*entity.Team, error) {
+	teams, err := repo.Team.GetAll()
+	if err != nil || len(teams) == 0 {
+		return nil, err
+	}
+	return &teams[random.Intn(len(teams))], nil
+}
+
+// checkRosters verifies every roster references distinct live players, and lists the same players as the team filter.
+func checkRosters(t *testing.T, repo repository.Repository) {
+	t.Helper()
+	teams, err := repo.Team.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	for _, team := range teams {
+		seen := make(map[int]struct{})
+		for _, playerID := range team.Players {
+			if _, duplicated := seen[playerID]; duplicated {
+				t.Errorf("team %d references player %d twice", team.ID, playerID)
+			}
+			seen[playerID] = struct{}{}
+			if _, err := repo.Player.GetByID(playerID); err != nil {
+				t.Errorf("team %d references player %d: %v", team.ID, playerID, err)
+			}
+		}
+		page, err := repo.Player.Find(repository.PlayerQuery{Filter: repository.PlayerFilter{TeamID: team.ID}})
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		if page.Total != len(team.Players) {
+			t.Errorf("team %d has %d players, the team filter finds %d", team.ID, len(team.Players), page.Total)
+		}
+	}
+}
+
+// snapshot returns the teams and the players sorted by ID.
+func snapshot(t *testing.T, repo repository.Repository) ([]entity.Team, []entity.Player) {
+	t.Helper()
+	teams, err := repo.Team.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll teams: %v", err)
+	}
+	players, err := repo.Player.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll players: %v", err)
+	}
+	slices.SortFunc(teams, func(a, b entity.Team) int { return a.ID - b.ID })
+	slices.SortFunc(players, func(a, b entity.Player) int { return a.ID - b.ID })
+	return teams, players
+}
@@ -0,0 +1,782 @@
+package repository_test
+
+import (
+	"errors"
+	"scoreplay/internal/database"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// backends are the repositories every contract test runs against.
+var backends = []struct {
+	name string
+	new  func(t *testing.T) repository.Repository
+}{
+	{name: "memory", new: func(t *testing.T) repository.Repository { return repository.NewInMemoryRepository() }},
+	{name: "sqlite", new: newSQLiteRepository},
+}
+
+// newSQLiteRepository opens a migrated SQLite database in memory, kept alive by the single connection of the pool.
+func newSQLiteRepository(t *testing.T) repository.Repository {
+	t.Helper()
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, DSN: ":memory:"})
+	if err != nil {
+		t.Fatalf("opening the database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db, database.DriverSQLite, repository.Backfills...)
+	if err != nil {
+		t.Fatalf("loading the migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("migrating the database: %v", err)
+	}
+	return repository.NewSQLRepository(db)
+}
+
+func TestRepositoryContract(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.Repository)
+	}{
+		{name: "create, read, update and delete players", run: testPlayerCRUD},
+		{name: "create, read, update and delete teams", run: testTeamCRUD},
+		{name: "find players by name, last name and team", run: testFindPlayers},
+		{name: "find teams by name, logo and player", run: testFindTeams},
+		{name: "page with offsets and cursors", run: testPaging},
+		{name: "sort on several fields", run: testSorting},
+		{name: "soft delete", run: testSoftDelete},
+		{name: "restore", run: testRestore},
+		{name: "purge", run: testPurge},
+		{name: "merge", run: testMerge},
+		{name: "version conflicts", run: testVersionConflicts},
+		{name: "rosters reference existing players", run: testRosterIntegrity},
+		{name: "record sync keeps the version", run: testRecordSync},
+		{name: "transaction commit", run: testTransactionCommit},
+		{name: "transaction rollback", run: testTransactionRollback},
+		{name: "history", run: testHistory},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			for _, test := range tests {
+				t.Run(test.name, func(t *testing.T) {
+					test.run(t, backend.new(t))
+				})
+			}
+		})
+	}
+}
+
+func testPlayerCRUD(t *testing.T, repo repository.Repository) {
+	saka := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	odegaard := mustCreatePlayer(t, repo, "Martin", "Ødegaard")
+	if saka.ID != 1 || odegaard.ID != 2 {
+		t.Errorf("IDs = %d, %d, want 1, 2", saka.ID, odegaard.ID)
+	}
+
+	found, err := repo.Player.GetByID(odegaard.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.FirstName != "Martin" || found.LastName != "Ødegaard" {
+		t.Errorf("player = %+v, want Martin Ødegaard", found)
+	}
+	_, err = repo.Player.GetByID(99)
+	assertError[exception.EntityNotFoundException](t, err)
+
+	found.ProfilePicture = "odegaard.png"
+	if _, err := repo.Player.Update(*found); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated, err := repo.Player.GetByID(odegaard.ID); err != nil || updated.ProfilePicture != "odegaard.png" {
+		t.Errorf("GetByID after the update = %+v, %v, want the new profile picture", updated, err)
+	}
+	_, err = repo.Player.Update(entity.Player{ID: 99, FirstName: "Nobody"})
+	assertError[exception.EntityNotFoundException](t, err)
+
+	players, err := repo.Player.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertIDs(t, sortedIDs(playerIDs(players)), []int{saka.ID, odegaard.ID})
+
+	if err := repo.Player.Delete(saka.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	_, err = repo.Player.GetByID(saka.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	assertError[exception.EntityNotFoundException](t, repo.Player.Delete(saka.ID))
+	// A deleted ID is not given again
+	if created := mustCreatePlayer(t, repo, "Kai", "Havertz"); created.ID != 3 {
+		t.Errorf("ID after a delete = %d, want 3", created.ID)
+	}
+}
+
+func testTeamCRUD(t *testing.T, repo repository.Repository) {
+	saka := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	odegaard := mustCreatePlayer(t, repo, "Martin", "Ødegaard")
+	havertz := mustCreatePlayer(t, repo, "Kai", "Havertz")
+	team := mustCreateTeam(t, repo, "Arsenal", odegaard.ID, saka.ID)
+
+	// The roster keeps the order the players were added in
+	found, err := repo.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Name != "Arsenal" {
+		t.Errorf("team = %+v, want Arsenal", found)
+	}
+	assertIDs(t, found.Players, []int{odegaard.ID, saka.ID})
+
+	found.Logo = "arsenal.png"
+	found.Players = []int{havertz.ID, odegaard.ID}
+	if _, err := repo.Team.Update(*found); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	players, err := repo.Team.GetPlayersByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetPlayersByID: %v", err)
+	}
+	assertIDs(t, playerIDs(players), []int{havertz.ID, odegaard.ID})
+	if updated, err := repo.Team.GetByID(team.ID); err != nil || updated.Logo != "arsenal.png" {
+		t.Errorf("GetByID after the update = %+v, %v, want the new logo", updated, err)
+	}
+	_, err = repo.Team.Update(entity.Team{ID: 99, Name: "Nowhere FC"})
+	assertError[exception.EntityNotFoundException](t, err)
+
+	other := mustCreateTeam(t, repo, "Chelsea")
+	teams, err := repo.Team.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertIDs(t, sortedIDs(teamIDs(teams)), []int{team.ID, other.ID})
+
+	if err := repo.Team.Delete(team.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	_, err = repo.Team.GetByID(team.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	_, err = repo.Team.GetPlayersByID(team.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	assertError[exception.EntityNotFoundException](t, repo.Team.Delete(team.ID))
+	// The players of a deleted team are kept
+	if _, err := repo.Player.GetByID(havertz.ID); err != nil {
+		t.Errorf("GetByID player of the deleted team: %v", err)
+	}
+}
+
+func testFindPlayers(t *testing.T, repo repository.Repository) {
+	saka := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	odegaard := mustCreatePlayer(t, repo, "Martin", "Ødegaard")
+	mustCreatePlayer(t, repo, "Kai", "Havertz")
+	team := mustCreateTeam(t, repo, "Arsenal", saka.ID, odegaard.ID)
+	emptyTeam := mustCreateTeam(t, repo, "Chelsea")
+
+	tests := []struct {
+		name   string
+		filter repository.PlayerFilter
+		want   []int
+	}{
+		{name: "no filter", filter: repository.PlayerFilter{}, want: []int{1, 2, 3}},
+		{name: "name ignoring case and diacritics", filter: repository.PlayerFilter{Name: "ODEGAARD"}, want: []int{odegaard.ID}},
+		{name: "name with diacritics", filter: repository.PlayerFilter{Name: "ØDEGAARD"}, want: []int{odegaard.ID}},
+		{name: "part of the full name", filter: repository.PlayerFilter{Name: "KAYO SA"}, want: []int{saka.ID}},
+		{name: "last name", filter: repository.PlayerFilter{LastName: "saka"}, want: []int{saka.ID}},
+		{name: "last name is not a prefix", filter: repository.PlayerFilter{LastName: "sak"}, want: []int{}},
+		{name: "team", filter: repository.PlayerFilter{TeamID: team.ID}, want: []int{saka.ID, odegaard.ID}},
+		{name: "team with an empty roster", filter: repository.PlayerFilter{TeamID: emptyTeam.ID}, want: []int{}},
+		{name: "missing team", filter: repository.PlayerFilter{TeamID: 99}, want: []int{}},
+		{name: "team and name", filter: repository.PlayerFilter{TeamID: team.ID, Name: "saka"}, want: []int{saka.ID}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := repo.Player.Find(repository.PlayerQuery{Filter: test.filter})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			assertIDs(t, playerIDs(page.Players), test.want)
+			if page.Total != len(test.want) {
+				t.Errorf("Total = %d, want %d", page.Total, len(test.want))
+			}
+		})
+	}
+}
+
+func testFindTeams(t *testing.T, repo repository.Repository) {
+	saka := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	arsenal := mustCreateTeam(t, repo, "Arsenal", saka.ID)
+	arsenal.Logo = "arsenal.png"
+	if _, err := repo.Team.Update(*arsenal); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	liverpool := mustCreateTeam(t, repo, "Liverpool")
+	atletico := mustCreateTeam(t, repo, "Atlético Madrid")
+	hasLogo, hasNoLogo := true, false
+
+	tests := []struct {
+		name   string
+		filter repository.TeamFilter
+		want   []int
+	}{
+		{name: "no filter", filter: repository.TeamFilter{}, want: []int{arsenal.ID, liverpool.ID, atletico.ID}},
+		{name: "part of the name ignoring case", filter: repository.TeamFilter{Name: "VERP"}, want: []int{liverpool.ID}},
+		{name: "name ignoring diacritics", filter: repository.TeamFilter{Name: "ATLETICO"}, want: []int{atletico.ID}},
+		{name: "name with diacritics", filter: repository.TeamFilter{Name: "ATLÉTICO M"}, want: []int{atletico.ID}},
+		{name: "name with a wildcard", filter: repository.TeamFilter{Name: "%"}, want: []int{}},
+		{name: "with a logo", filter: repository.TeamFilter{HasLogo: &hasLogo}, want: []int{arsenal.ID}},
+		{name: "without a logo", filter: repository.TeamFilter{HasLogo: &hasNoLogo}, want: []int{liverpool.ID, atletico.ID}},
+		{name: "player", filter: repository.TeamFilter{PlayerID: saka.ID}, want: []int{arsenal.ID}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := repo.Team.Find(repository.TeamQuery{Filter: test.filter})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			assertIDs(t, teamIDs(page.Teams), test.want)
+			if page.Total != len(test.want) {
+				t.Errorf("Total = %d, want %d", page.Total, len(test.want))
+			}
+		})
+	}
+}
+
+func testPaging(t *testing.T, repo repository.Repository) {
+	for _, lastName := range []string{"Saka", "Ødegaard", "Havertz", "Saliba", "Rice"} {
+		mustCreatePlayer(t, repo, "Player", lastName)
+	}
+
+	tests := []struct {
+		name       string
+		page       repository.Page
+		want       []int
+		nextCursor bool
+	}{
+		{name: "first page", page: repository.Page{Limit: 2}, want: []int{1, 2}, nextCursor: true},
+		{name: "page from an offset", page: repository.Page{Offset: 2, Limit: 2}, want: []int{3, 4}, nextCursor: true},
+		{name: "last page", page: repository.Page{Offset: 4, Limit: 2}, want: []int{5}},
+		{name: "offset past the end", page: repository.Page{Offset: 10, Limit: 2}, want: []int{}},
+		{name: "no limit", page: repository.Page{}, want: []int{1, 2, 3, 4, 5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := repo.Player.Find(repository.PlayerQuery{Page: test.page})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			assertIDs(t, playerIDs(page.Players), test.want)
+			if page.Total != 5 {
+				t.Errorf("Total = %d, want 5", page.Total)
+			}
+			if (page.NextCursor != "") != test.nextCursor {
+				t.Errorf("NextCursor = %q, want a cursor: %t", page.NextCursor, test.nextCursor)
+			}
+		})
+	}
+
+	t.Run("cursors", func(t *testing.T) {
+		sort := repository.ParseSort("-last_name")
+		var seen []int
+		cursor := ""
+		for pages := 0; pages == 0 || cursor != ""; pages++ {
+			if pages > 5 {
+				t.Fatalf("the cursors never end, seen %v", seen)
+			}
+			page, err := repo.Player.Find(repository.PlayerQuery{Sort: sort, Page: repository.Page{Limit: 2, Cursor: cursor}})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			seen = append(seen, playerIDs(page.Players)...)
+			cursor = page.NextCursor
+		}
+		// Ødegaard sorts after the other names, byte-wise
+		assertIDs(t, seen, []int{2, 4, 1, 5, 3})
+	})
+
+	t.Run("cursor with another sort", func(t *testing.T) {
+		page, err := repo.Player.Find(repository.PlayerQuery{Page: repository.Page{Limit: 2}})
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		_, err = repo.Player.Find(repository.PlayerQuery{Sort: repository.ParseSort("last_name"), Page: repository.Page{Cursor: page.NextCursor}})
+		assertError[exception.InvalidArgumentException](t, err)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		_, err := repo.Team.Find(repository.TeamQuery{Page: repository.Page{Cursor: "not a cursor"}})
+		assertError[exception.InvalidArgumentException](t, err)
+	})
+}
+
+func testSorting(t *testing.T, repo repository.Repository) {
+	mustCreatePlayer(t, repo, "Gabriel", "Jesus")
+	mustCreatePlayer(t, repo, "Gabriel", "Martinelli")
+	mustCreatePlayer(t, repo, "Ben", "White")
+	mustCreatePlayer(t, repo, "Aaron", "Ramsdale")
+
+	tests := []struct {
+		sort string
+		want []int
+	}{
+		{sort: "", want: []int{1, 2, 3, 4}},
+		{sort: "-id", want: []int{4, 3, 2, 1}},
+		{sort: "last_name", want: []int{1, 2, 4, 3}},
+		{sort: "first_name,-last_name", want: []int{4, 3, 2, 1}},
+		{sort: "-first_name, id", want: []int{1, 2, 3, 4}},
+	}
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			page, err := repo.Player.Find(repository.PlayerQuery{Sort: repository.ParseSort(test.sort)})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			assertIDs(t, playerIDs(page.Players), test.want)
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := repo.Player.Find(repository.PlayerQuery{Sort: repository.ParseSort("shirt_number")})
+		assertError[exception.InvalidArgumentException](t, err)
+	})
+}
+
+func testSoftDelete(t *testing.T, repo repository.Repository) {
+	player := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	team := mustCreateTeam(t, repo, "Arsenal", player.ID)
+
+	if err := repo.Team.Delete(team.ID); err != nil {
+		t.Fatalf("Delete team: %v", err)
+	}
+	if err := repo.Player.Delete(player.ID); err != nil {
+		t.Fatalf("Delete player: %v", err)
+	}
+
+	_, err := repo.Team.GetByID(team.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	_, err = repo.Player.GetByID(player.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	assertError[exception.EntityNotFoundException](t, repo.Team.Delete(team.ID))
+	assertError[exception.EntityNotFoundException](t, repo.Player.Delete(player.ID))
+
+	teams, err := repo.Team.GetAll()
+	if err != nil || len(teams) != 0 {
+		t.Errorf("GetAll teams = %v, %v, want none", teamIDs(teams), err)
+	}
+	teamPage, err := repo.Team.Find(repository.TeamQuery{})
+	if err != nil || teamPage.Total != 0 {
+		t.Errorf("Find teams = %+v, %v, want none", teamPage, err)
+	}
+	playerPage, err := repo.Player.Find(repository.PlayerQuery{})
+	if err != nil || playerPage.Total != 0 {
+		t.Errorf("Find players = %+v, %v, want none", playerPage, err)
+	}
+
+	deletedTeams, err := repo.Team.GetDeleted()
+	if err != nil {
+		t.Fatalf("GetDeleted teams: %v", err)
+	}
+	if len(deletedTeams) != 1 || deletedTeams[0].DeletedAt == nil || deletedTeams[0].Version != team.Version+1 {
+		t.Errorf("GetDeleted teams = %+v, want the team deleted at version %d", deletedTeams, team.Version+1)
+	}
+	// The roster of a deleted team is kept until it is purged
+	if len(deletedTeams) == 1 && len(deletedTeams[0].Players) != 1 {
+		t.Errorf("deleted team roster = %v, want [%d]", deletedTeams[0].Players, player.ID)
+	}
+	deletedPlayers, err := repo.Player.GetDeleted()
+	if err != nil || len(deletedPlayers) != 1 {
+		t.Errorf("GetDeleted players = %v, %v, want [%d]", playerIDs(deletedPlayers), err, player.ID)
+	}
+}
+
+func testRestore(t *testing.T, repo repository.Repository) {
+	player := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	team := mustCreateTeam(t, repo, "Arsenal", player.ID)
+
+	_, err := repo.Team.Restore(team.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	_, err = repo.Player.Restore(player.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+
+	if err := repo.Team.Delete(team.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	restored, err := repo.Team.Restore(team.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Version != team.Version+2 {
+		t.Errorf("restored team = %+v, want it undeleted at version %d", restored, team.Version+2)
+	}
+	found, err := repo.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertIDs(t, found.Players, []int{player.ID})
+	if deleted, err := repo.Team.GetDeleted(); err != nil || len(deleted) != 0 {
+		t.Errorf("GetDeleted = %v, %v, want none", teamIDs(deleted), err)
+	}
+
+	if err := repo.Player.Delete(player.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	restoredPlayer, err := repo.Player.Restore(player.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restoredPlayer.DeletedAt != nil || restoredPlayer.LastName != "Saka" {
+		t.Errorf("restored player = %+v, want Saka undeleted", restoredPlayer)
+	}
+}
+
+func testPurge(t *testing.T, repo repository.Repository) {
+	kept := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	purged := mustCreatePlayer(t, repo, "Kai", "Havertz")
+	team := mustCreateTeam(t, repo, "Arsenal", kept.ID)
+	if err := repo.Player.Delete(purged.ID); err != nil {
+		t.Fatalf("Delete player: %v", err)
+	}
+	if err := repo.Team.Delete(team.ID); err != nil {
+		t.Fatalf("Delete team: %v", err)
+	}
+
+	// Nothing was deleted an hour ago
+	if ids, err := repo.Player.Purge(time.Now().UTC().Add(-time.Hour)); err != nil || len(ids) != 0 {
+		t.Errorf("Purge players = %v, %v, want none", ids, err)
+	}
+	if ids, err := repo.Team.Purge(time.Now().UTC().Add(-time.Hour)); err != nil || len(ids) != 0 {
+		t.Errorf("Purge teams = %v, %v, want none", ids, err)
+	}
+
+	ids, err := repo.Player.Purge(time.Now().UTC().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Purge players: %v", err)
+	}
+	assertIDs(t, ids, []int{purged.ID})
+	ids, err = repo.Team.Purge(time.Now().UTC().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Purge teams: %v", err)
+	}
+	assertIDs(t, ids, []int{team.ID})
+
+	for name, deleted := range map[string]func() (int, error){
+		"teams":   func() (int, error) { teams, err := repo.Team.GetDeleted(); return len(teams), err },
+		"players": func() (int, error) { players, err := repo.Player.GetDeleted(); return len(players), err },
+	} {
+		if count, err := deleted(); err != nil || count != 0 {
+			t.Errorf("GetDeleted %s = %d, %v, want none", name, count, err)
+		}
+	}
+	_, err = repo.Team.Restore(team.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	// The players of a purged team are not purged with it
+	if _, err := repo.Player.GetByID(kept.ID); err != nil {
+		t.Errorf("GetByID player of the purged team: %v", err)
+	}
+}
+
+func testMerge(t *testing.T, repo repository.Repository) {
+	player := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	duplicate := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+
+	_, err := repo.Player.GetMergedInto(duplicate.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+	if err := repo.Player.Merge(duplicate.ID, player.ID); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	intoID, err := repo.Player.GetMergedInto(duplicate.ID)
+	if err != nil || intoID != player.ID {
+		t.Errorf("GetMergedInto = %d, %v, want %d", intoID, err, player.ID)
+	}
+	_, err = repo.Player.GetByID(duplicate.ID)
+	assertError[exception.EntityNotFoundException](t, err)
+
+	// A merged player stays out of the trash, so it is never restored nor purged
+	if deleted, err := repo.Player.GetDeleted(); err != nil || len(deleted) != 0 {
+		t.Errorf("GetDeleted = %v, %v, want none", playerIDs(deleted), err)
+	}
+	if ids, err := repo.Player.Purge(time.Now().UTC().Add(time.Second)); err != nil || len(ids) != 0 {
+		t.Errorf("Purge = %v, %v, want none", ids, err)
+	}
+	if intoID, err := repo.Player.GetMergedInto(duplicate.ID); err != nil || intoID != player.ID {
+		t.Errorf("GetMergedInto after purge = %d, %v, want %d", intoID, err, player.ID)
+	}
+}
+
+func testVersionConflicts(t *testing.T, repo repository.Repository) {
+	player := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	if player.Version != 1 {
+		t.Errorf("created player version = %d, want 1", player.Version)
+	}
+	player.ProfilePicture = "saka.png"
+	updated, err := repo.Player.Update(*player)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Version != 2 || updated.ProfilePicture != "saka.png" {
+		t.Errorf("updated player = %+v, want the new profile picture at version 2", updated)
+	}
+
+	// The stale copy still has version 1
+	_, err = repo.Player.Update(*player)
+	conflict := assertError[exception.VersionConflictException](t, err)
+	if conflict.ExpectedVersion != 1 || conflict.ActualVersion != 2 {
+		t.Errorf("conflict = %+v, want expected 1 and actual 2", conflict)
+	}
+	_, err = repo.Player.Update(entity.Player{ID: 99, FirstName: "Nobody", Version: 1})
+	assertError[exception.EntityNotFoundException](t, err)
+
+	team := mustCreateTeam(t, repo, "Arsenal")
+	team.Players = []int{player.ID}
+	if _, err := repo.Team.Update(*team); err != nil {
+		t.Fatalf("Update team: %v", err)
+	}
+	_, err = repo.Team.Update(*team)
+	assertError[exception.VersionConflictException](t, err)
+	// The roster of the stale update is not applied
+	found, err := repo.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Version != 2 || len(found.Players) != 1 {
+		t.Errorf("team = %+v, want one player at version 2", found)
+	}
+
+	if err := repo.Team.Delete(team.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	found.Name = "Woolwich Arsenal"
+	_, err = repo.Team.Update(*found)
+	assertError[exception.EntityNotFoundException](t, err)
+}
+
+func testRosterIntegrity(t *testing.T, repo repository.Repository) {
+	player := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+
+	_, err := repo.Team.Create(entity.Team{Name: "Arsenal", Players: []int{player.ID, 99}})
+	assertError[exception.ReferentialIntegrityException](t, err)
+
+	team := mustCreateTeam(t, repo, "Arsenal", player.ID)
+	team.Players = nil
+	team, err = repo.Team.Update(*team)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.Player.Delete(player.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	team.Players = []int{player.ID}
+	_, err = repo.Team.Update(*team)
+	assertError[exception.ReferentialIntegrityException](t, err)
+}
+
+func testRecordSync(t *testing.T, repo repository.Repository) {
+	team := mustCreateTeam(t, repo, "Arsenal")
+	syncedAt := time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)
+	if err := repo.Team.RecordSync(team.ID, syncedAt, "succeeded"); err != nil {
+		t.Fatalf("RecordSync: %v", err)
+	}
+	found, err := repo.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Version != team.Version || found.LastSyncedAt == nil || !found.LastSyncedAt.Equal(syncedAt) || found.LastSyncStatus != "succeeded" {
+		t.Errorf("team = %+v, want synced at %s with version %d", found, syncedAt, team.Version)
+	}
+
+	// An update does not change the outcome of the last sync
+	found.Name = "The Arsenal"
+	found.LastSyncedAt, found.LastSyncStatus = nil, ""
+	updated, err := repo.Team.Update(*found)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.LastSyncedAt == nil || updated.LastSyncStatus != "succeeded" {
+		t.Errorf("updated team = %+v, want the last sync kept", updated)
+	}
+	assertError[exception.EntityNotFoundException](t, repo.Team.RecordSync(99, syncedAt, "failed"))
+}
+
+func testTransactionCommit(t *testing.T, repo repository.Repository) {
+	var team *entity.Team
+	err := repo.InTransaction(func(tx repository.Transaction) error {
+		player, err := tx.Player().Create(entity.Player{FirstName: "Bukayo", LastName: "Saka"})
+		if err != nil {
+			return err
+		}
+		// The changes are visible within the transaction
+		if _, err := tx.Player().GetByID(player.ID); err != nil {
+			return err
+		}
+		team, err = tx.Team().Create(entity.Team{Name: "Arsenal", Players: []int{player.ID}})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("InTransaction: %v", err)
+	}
+	found, err := repo.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	players, err := repo.Team.GetPlayersByID(found.ID)
+	if err != nil || len(players) != 1 {
+		t.Errorf("GetPlayersByID = %v, %v, want one player", playerIDs(players), err)
+	}
+}
+
+func testTransactionRollback(t *testing.T, repo repository.Repository) {
+	player := mustCreatePlayer(t, repo, "Bukayo", "Saka")
+	team := mustCreateTeam(t, repo, "Arsenal", player.ID)
+	failure := errors.New("failure")
+
+	err := repo.InTransaction(func(tx repository.Transaction) error {
+		created, err := tx.Player().Create(entity.Player{FirstName: "Kai", LastName: "Havertz"})
+		if err != nil {
+			return err
+		}
+		team.Players = []int{created.ID}
+		if _, err := tx.Team().Update(*team); err != nil {
+			return err
+		}
+		if err := tx.Player().Delete(player.ID); err != nil {
+			return err
+		}
+		if _, err := tx.History().Create(entity.HistoryEntry{EntityType: repository.HistoryEntityTeam, EntityID: team.ID,
+			Action: repository.HistoryActionUpdate, Timestamp: time.Now().UTC()}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("InTransaction = %v, want %v", err, failure)
+	}
+
+	found, err := repo.Team.GetByID(team.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if found.Version != 1 {
+		t.Errorf("team version = %d, want 1", found.Version)
+	}
+	assertIDs(t, found.Players, []int{player.ID})
+	if _, err := repo.Player.GetByID(player.ID); err != nil {
+		t.Errorf("GetByID of the player deleted by the rollback: %v", err)
+	}
+	players, err := repo.Player.GetAll()
+	if err != nil || len(players) != 1 {
+		t.Errorf("GetAll = %v, %v, want [%d]", playerIDs(players), err, player.ID)
+	}
+	if entries, total, err := repo.History.GetByEntity(repository.HistoryEntityTeam, team.ID, 0, 10); err != nil || total != 0 {
+		t.Errorf("GetByEntity = %v, %d, %v, want none", entries, total, err)
+	}
+
+	// The repositories can be used again once the transaction is rolled back
+	if _, err := repo.Player.Create(entity.Player{FirstName: "Declan", LastName: "Rice"}); err != nil {
+		t.Errorf("Create after the rollback: %v", err)
+	}
+}
+
+func testHistory(t *testing.T, repo repository.Repository) {
+	timestamp := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for _, action := range []string{repository.HistoryActionCreate, repository.HistoryActionUpdate, repository.HistoryActionSync} {
+		_, err := repo.History.Create(entity.HistoryEntry{
+			EntityType: repository.HistoryEntityTeam,
+			EntityID:   1,
+			Action:     action,
+			Actor:      "tester",
+			Source:     repository.HistorySourceAPI,
+			Timestamp:  timestamp,
+			Changes:    []entity.FieldChange{{Field: "name", Before: nil, After: "Arsenal"}},
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := repo.History.Create(entity.HistoryEntry{EntityType: repository.HistoryEntityPlayer, EntityID: 1,
+		Action: repository.HistoryActionCreate, Timestamp: timestamp}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	entries, total, err := repo.History.GetByEntity(repository.HistoryEntityTeam, 1, 1, 10)
+	if err != nil {
+		t.Fatalf("GetByEntity: %v", err)
+	}
+	if total != 3 || len(entries) != 2 {
+		t.Fatalf("GetByEntity = %d entries of %d, want 2 of 3", len(entries), total)
+	}
+	// Most recent first
+	if entries[0].Action != repository.HistoryActionUpdate || entries[1].Action != repository.HistoryActionCreate {
+		t.Errorf("actions = %s, %s, want update, create", entries[0].Action, entries[1].Action)
+	}
+	if entries[0].Actor != "tester" || !entries[0].Timestamp.Equal(timestamp) || len(entries[0].Changes) != 1 ||
+		entries[0].Changes[0].After != "Arsenal" {
+		t.Errorf("entry = %+v, want the change of the name by tester", entries[0])
+	}
+}
+
+func mustCreatePlayer(t *testing.T, repo repository.Repository, firstName string, lastName string) *entity.Player {
+	t.Helper()
+	player, err := repo.Player.Create(entity.Player{FirstName: firstName, LastName: lastName})
+	if err != nil {
+		t.Fatalf("Create player: %v", err)
+	}
+	return player
+}
+
+func mustCreateTeam(t *testing.T, repo repository.Repository, name string, playerIDs ...int) *entity.Team {
+	t.Helper()
+	team, err := repo.Team.Create(entity.Team{Name: name, Players: playerIDs})
+	if err != nil {
+		t.Fatalf("Create team: %v", err)
+	}
+	return team
+}
+
+// assertError fails the test unless the error is, or wraps, an exception of type E, which it returns.
+func assertError[E error](t *testing.T, err error) E {
+	t.Helper()
+	var target E
+	if !errors.As(err, &target) {
+		t.Errorf("error = %v, want a %T", err, target)
+	}
+	return target
+}
+
+func assertIDs(t *testing.T, got []int, want []int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("IDs = %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+// sortedIDs sorts the IDs of an unordered list, like the in-memory GetAll.
+func sortedIDs(ids []int) []int {
+	slices.Sort(ids)
+	return ids
+}
+
+func playerIDs(players []entity.Player) []int {
+	ids := []int{}
+	for _, player := range players {
+		ids = append(ids, player.ID)
+	}
+	return ids
+}
+
+func teamIDs(teams []entity.Team) []int {
+	ids := []int{}
+	for _, team := range teams {
+		ids = append(ids, team.ID)
+	}
+	return ids
+}
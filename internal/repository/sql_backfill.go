@@ -0,0 +1,9 @@
+package repository
+
+import "scoreplay/internal/database"
+
+// Backfills complete the migrations adding the columns of values computed in Go, to be given to the migrator.
+var Backfills = []database.Backfill{
+	{Version: 12, Fill: backfillPlayerSearchNames},
+	{Version: 14, Fill: backfillTeamSearchNames},
+}
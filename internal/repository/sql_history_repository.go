@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"encoding/json"
+	"scoreplay/internal/entity"
+)
+
+type SQLHistoryRepository struct {
+	db sqlExecutor
+}
+
+func NewSQLHistoryRepository(db sqlExecutor) *SQLHistoryRepository {
+	return &SQLHistoryRepository{
+		db: db,
+	}
+}
+
+func (repo *SQLHistoryRepository) Create(entry entity.HistoryEntry) (*entity.HistoryEntry, error) {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return nil, err
+	}
+	err = repo.db.QueryRow(`INSERT INTO history (entity_type, entity_id, action, actor, source, created_at, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		entry.EntityType, entry.EntityID, entry.Action, entry.Actor, entry.Source, entry.Timestamp, string(changes)).Scan(&entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (repo *SQLHistoryRepository) GetByEntity(entityType string, entityID int, offset int, limit int) ([]entity.HistoryEntry, int, error) {
+	var total int
+	err := repo.db.QueryRow(`SELECT COUNT(*) FROM history WHERE entity_type = $1 AND entity_id = $2`, entityType, entityID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repo.db.Query(`SELECT id, entity_type, entity_id, action, actor, source, created_at, changes FROM history
+		WHERE entity_type = $1 AND entity_id = $2 ORDER BY id DESC LIMIT $3 OFFSET $4`, entityType, entityID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []entity.HistoryEntry
+	for rows.Next() {
+		var entry entity.HistoryEntry
+		var changes string
+		if err := rows.Scan(&entry.ID, &entry.EntityType, &entry.EntityID, &entry.Action, &entry.Actor, &entry.Source,
+			&entry.Timestamp, &changes); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/names"
+	"sort"
+	"time"
+)
+
+// playerColumns are the columns of a player, in the order of playerFields.
+const playerColumns = `id, first_name, last_name, display_name, known_as, native_name, profile_picture, ` +
+	playerProfileColumns + `, external_ids, edited_fields, version`
+
+// playerProfileColumns are the columns of the profile of a player, in the order of playerProfileValues.
+const playerProfileColumns = `position, shirt_number, nationality, date_of_birth, height, weight, preferred_foot, status,
+	cutout, render`
+
+// playerFields returns the destinations of the playerColumns of a row.
+func playerFields(player *entity.Player) []any {
+	return []any{&player.ID, &player.FirstName, &player.LastName, &player.DisplayName, &player.KnownAs, &player.NativeName,
+		&player.ProfilePicture, &player.Position,
+		&player.ShirtNumber, &player.Nationality, &player.DateOfBirth, &player.Height, &player.Weight, &player.PreferredFoot,
+		&player.Status, &player.Cutout, &player.Render, jsonColumn{&player.ExternalIDs}, jsonColumn{&player.EditedFields},
+		&player.Version}
+}
+
+// playerProfileValues returns the values of the playerProfileColumns of a player.
+func playerProfileValues(player entity.Player) []any {
+	return []any{player.Position, player.ShirtNumber, player.Nationality, player.DateOfBirth, player.Height, player.Weight,
+		player.PreferredFoot, player.Status, player.Cutout, player.Render}
+}
+
+type SQLPlayerRepository struct {
+	db sqlExecutor
+}
+
+func NewSQLPlayerRepository(db sqlExecutor) *SQLPlayerRepository {
+	return &SQLPlayerRepository{
+		db: db,
+	}
+}
+
+func (repo *SQLPlayerRepository) GetAll() ([]entity.Player, error) {
+	return repo.queryPlayers(`SELECT ` + playerColumns + ` FROM players WHERE deleted_at IS NULL ORDER BY id`)
+}
+
+func (repo *SQLPlayerRepository) Find(query PlayerQuery) (*PlayerPage, error) {
+	sortFields, err := normalizeSort(query.Sort, playerSortableFields)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := sqlConditions{}
+	conditions.add(`deleted_at IS NULL`)
+	if query.Filter.Name != "" {
+		conditions.add(`search_name LIKE ? ESCAPE '\'`, likePattern(names.Normalize(query.Filter.Name)))
+	}
+	if query.Filter.LastName != "" {
+		conditions.add(`search_last_name = ?`, names.Normalize(query.Filter.LastName))
+	}
+	if query.Filter.TeamID != 0 {
+		conditions.add(`id IN (SELECT team_players.player_id FROM team_players JOIN teams ON teams.id = team_players.team_id
+			WHERE team_players.team_id = ? AND teams.deleted_at IS NULL)`, query.Filter.TeamID)
+	}
+
+	page := &PlayerPage{}
+	if err := repo.db.QueryRow(`SELECT COUNT(*) FROM players`+conditions.where(), conditions.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+	clauses, args, err := findPage(conditions, sortFields, query.Page, playerSortableFields)
+	if err != nil {
+		return nil, err
+	}
+	if page.Players, err = repo.queryPlayers(`SELECT `+playerColumns+` FROM players`+clauses, args...); err != nil {
+		return nil, err
+	}
+	if query.Page.Limit > 0 && len(page.Players) > query.Page.Limit {
+		page.Players = page.Players[:query.Page.Limit]
+		page.NextCursor = encodeCursor(page.Players[len(page.Players)-1], sortFields, playerSortableFields)
+	}
+	return page, nil
+}
+
+func (repo *SQLPlayerRepository) GetByID(id int) (*entity.Player, error) {
+	var player entity.Player
+	err := repo.db.QueryRow(`SELECT `+playerColumns+` FROM players WHERE id = $1 AND deleted_at IS NULL`, id).Scan(playerFields(&player)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, exception.EntityNotFoundException{Entity: "Player", ID: id}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+func (repo *SQLPlayerRepository) Create(player entity.Player) (*entity.Player, error) {
+	args := append([]any{player.FirstName, player.LastName, player.DisplayName, player.KnownAs, player.NativeName,
+		playerSearchName(player), names.Normalize(player.LastName), player.ProfilePicture, jsonColumn{player.ExternalIDs},
+		jsonColumn{player.EditedFields}}, playerProfileValues(player)...)
+	err := repo.db.QueryRow(`INSERT INTO players (first_name, last_name, display_name, known_as, native_name, search_name,
+		search_last_name, profile_picture, external_ids, edited_fields, `+playerProfileColumns+`, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, 1)
+		RETURNING id, version`, args...).
+		Scan(&player.ID, &player.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+func (repo *SQLPlayerRepository) Update(player entity.Player) (*entity.Player, error) {
+	args := append([]any{player.FirstName, player.LastName, player.DisplayName, player.KnownAs, player.NativeName,
+		playerSearchName(player), names.Normalize(player.LastName), player.ProfilePicture, jsonColumn{player.ExternalIDs},
+		jsonColumn{player.EditedFields}}, playerProfileValues(player)...)
+	result, err := repo.db.Exec(`UPDATE players SET first_name = $1, last_name = $2, display_name = $3, known_as = $4,
+		native_name = $5, search_name = $6, search_last_name = $7, profile_picture = $8, external_ids = $9, edited_fields = $10,
+		position = $11, shirt_number = $12, nationality = $13, date_of_birth = $14, height = $15, weight = $16,
+		preferred_foot = $17, status = $18, cutout = $19, render = $20, version = version + 1
+		WHERE id = $21 AND version = $22 AND deleted_at IS NULL`, append(args, player.ID, player.Version)...)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectVersion(repo.db, result, "players", "Player", player.ID, player.Version); err != nil {
+		return nil, err
+	}
+	player.Version++
+	return &player, nil
+}
+
+func (repo *SQLPlayerRepository) Delete(id int) error {
+	result, err := repo.db.Exec(`UPDATE players SET deleted_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL`,
+		time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "Player", id)
+}
+
+func (repo *SQLPlayerRepository) Merge(id int, intoID int) error {
+	result, err := repo.db.Exec(`UPDATE players SET deleted_at = $1, merged_into = $2, version = version + 1
+		WHERE id = $3 AND deleted_at IS NULL`, time.Now().UTC(), intoID, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "Player", id)
+}
+
+func (repo *SQLPlayerRepository) GetMergedInto(id int) (int, error) {
+	var intoID int
+	err := repo.db.QueryRow(`SELECT merged_into FROM players WHERE id = $1 AND merged_into IS NOT NULL`, id).Scan(&intoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, exception.EntityNotFoundException{Entity: "Player", ID: id}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return intoID, nil
+}
+
+func (repo *SQLPlayerRepository) GetDeleted() ([]entity.Player, error) {
+	rows, err := repo.db.Query(`SELECT ` + playerColumns + `, deleted_at FROM players
+		WHERE deleted_at IS NOT NULL AND merged_into IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []entity.Player
+	for rows.Next() {
+		var player entity.Player
+		var deletedAt sql.NullTime
+		if err := rows.Scan(append(playerFields(&player), &deletedAt)...); err != nil {
+			return nil, err
+		}
+		player.DeletedAt = &deletedAt.Time
+		players = append(players, player)
+	}
+	return players, rows.Err()
+}
+
+func (repo *SQLPlayerRepository) Restore(id int) (*entity.Player, error) {
+	result, err := repo.db.Exec(`UPDATE players SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL AND merged_into IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectAffected(result, "Player", id); err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+func (repo *SQLPlayerRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	var ids []int
+	err := withSQLTransaction(repo.db, func(tx sqlExecutor) error {
+		// Only the rosters of deleted teams can still reference a deleted player
+		if _, err := tx.Exec(`DELETE FROM team_players WHERE player_id IN
+			(SELECT id FROM players WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND merged_into IS NULL)`, deletedBefore); err != nil {
+			return err
+		}
+		var err error
+		ids, err = queryIDs(tx, `DELETE FROM players WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND merged_into IS NULL
+			RETURNING id`, deletedBefore)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// backfillPlayerSearchNames stores the normalized names the players are searched by, for the players written before
+// the search columns existed. The search names are derived from the names, so the versions are left as they are.
+func backfillPlayerSearchNames(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, first_name, last_name, display_name, known_as, native_name FROM players`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var players []entity.Player
+	for rows.Next() {
+		var player entity.Player
+		if err := rows.Scan(&player.ID, &player.FirstName, &player.LastName, &player.DisplayName, &player.KnownAs,
+			&player.NativeName); err != nil {
+			return err
+		}
+		players = append(players, player)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, player := range players {
+		if _, err := tx.Exec(`UPDATE players SET search_name = $1, search_last_name = $2 WHERE id = $3`,
+			playerSearchName(player), names.Normalize(player.LastName), player.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryPlayers runs a query selecting the playerColumns of players.
+func (repo *SQLPlayerRepository) queryPlayers(query string, args ...any) ([]entity.Player, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []entity.Player{}
+	for rows.Next() {
+		var player entity.Player
+		if err := rows.Scan(playerFields(&player)...); err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+	return players, rows.Err()
+}
+
+// expectAffected turns an update or delete that matched no rows into an EntityNotFoundException.
+func expectAffected(result sql.Result, entityName string, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return exception.EntityNotFoundException{Entity: entityName, ID: id}
+	}
+	return nil
+}
+
+// expectVersion explains an update that matched no rows, either the entity does not exist or its version changed.
+func expectVersion(db sqlExecutor, result sql.Result, table string, entityName string, id int, version int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var actualVersion int
+	err = db.QueryRow(`SELECT version FROM `+table+` WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&actualVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return exception.EntityNotFoundException{Entity: entityName, ID: id}
+	}
+	if err != nil {
+		return err
+	}
+	return exception.VersionConflictException{Entity: entityName, ID: id, ExpectedVersion: version, ActualVersion: actualVersion}
+}
+
+// queryIDs runs a query returning a single ID column, and returns the IDs in ascending order.
+func queryIDs(db sqlExecutor, query string, args ...any) ([]int, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// jsonColumn stores a value as JSON in a text column, scanning it back into a pointer.
+type jsonColumn struct {
+	value any
+}
+
+func (c jsonColumn) Value() (driver.Value, error) {
+	content, err := json.Marshal(c.value)
+	if err != nil {
+		return nil, err
+	}
+	return string(content), nil
+}
+
+func (c jsonColumn) Scan(src any) error {
+	switch content := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(content), c.value)
+	case []byte:
+		return json.Unmarshal(content, c.value)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into a JSON column", src)
+	}
+}
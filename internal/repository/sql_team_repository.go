@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/names"
+	"time"
+)
+
+// teamColumns are the columns of a team, in the order of teamFields.
+const teamColumns = `id, name, logo, ` + teamProfileColumns + `, provider, external_ids, sync_schedule, last_synced_at,
+	last_sync_status, version`
+
+// teamProfileColumns are the columns of the profile of a team, in the order of teamProfileValues.
+const teamProfileColumns = `short_name, alternate_names, formed_year, sport, league, country, stadium, stadium_capacity,
+	colors, badge, banner, jersey, fanart, website, social_links, description, edited_fields`
+
+// teamFields returns the destinations of the teamColumns of a row.
+func teamFields(team *entity.Team) []any {
+	return []any{&team.ID, &team.Name, &team.Logo, &team.ShortName, jsonColumn{&team.AlternateNames}, &team.FormedYear,
+		&team.Sport, &team.League, &team.Country, &team.Stadium, &team.StadiumCapacity, jsonColumn{&team.Colors}, &team.Badge,
+		&team.Banner, &team.Jersey, jsonColumn{&team.Fanart}, &team.Website, jsonColumn{&team.SocialLinks}, &team.Description,
+		jsonColumn{&team.EditedFields}, &team.Provider, jsonColumn{&team.ExternalIDs}, &team.SyncSchedule, &team.LastSyncedAt,
+		&team.LastSyncStatus, &team.Version}
+}
+
+// teamProfileValues returns the values of the teamProfileColumns of a team.
+func teamProfileValues(team entity.Team) []any {
+	return []any{team.ShortName, jsonColumn{team.AlternateNames}, team.FormedYear, team.Sport, team.League, team.Country,
+		team.Stadium, team.StadiumCapacity, jsonColumn{team.Colors}, team.Badge, team.Banner, team.Jersey,
+		jsonColumn{team.Fanart}, team.Website, jsonColumn{team.SocialLinks}, team.Description, jsonColumn{team.EditedFields}}
+}
+
+type SQLTeamRepository struct {
+	db sqlExecutor
+}
+
+func NewSQLTeamRepository(db sqlExecutor) *SQLTeamRepository {
+	return &SQLTeamRepository{
+		db: db,
+	}
+}
+
+func (repo *SQLTeamRepository) GetAll() ([]entity.Team, error) {
+	return repo.queryTeams(`SELECT ` + teamColumns + ` FROM teams WHERE deleted_at IS NULL ORDER BY id`)
+}
+
+func (repo *SQLTeamRepository) Find(query TeamQuery) (*TeamPage, error) {
+	sortFields, err := normalizeSort(query.Sort, teamSortableFields)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := sqlConditions{}
+	conditions.add(`deleted_at IS NULL`)
+	if query.Filter.Name != "" {
+		conditions.add(`search_name LIKE ? ESCAPE '\'`, likePattern(names.Normalize(query.Filter.Name)))
+	}
+	if query.Filter.HasLogo != nil {
+		if *query.Filter.HasLogo {
+			conditions.add(`logo <> ''`)
+		} else {
+			conditions.add(`logo = ''`)
+		}
+	}
+	if query.Filter.PlayerID != 0 {
+		conditions.add(`EXISTS (SELECT 1 FROM team_players WHERE team_players.team_id = teams.id AND team_players.player_id = ?)`,
+			query.Filter.PlayerID)
+	}
+
+	page := &TeamPage{}
+	if err := repo.db.QueryRow(`SELECT COUNT(*) FROM teams`+conditions.where(), conditions.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+	clauses, args, err := findPage(conditions, sortFields, query.Page, teamSortableFields)
+	if err != nil {
+		return nil, err
+	}
+	if page.Teams, err = repo.queryTeams(`SELECT `+teamColumns+` FROM teams`+clauses, args...); err != nil {
+		return nil, err
+	}
+	if query.Page.Limit > 0 && len(page.Teams) > query.Page.Limit {
+		page.Teams = page.Teams[:query.Page.Limit]
+		page.NextCursor = encodeCursor(page.Teams[len(page.Teams)-1], sortFields, teamSortableFields)
+	}
+	return page, nil
+}
+
+func (repo *SQLTeamRepository) GetByID(id int) (*entity.Team, error) {
+	var team entity.Team
+	err := repo.db.QueryRow(`SELECT `+teamColumns+` FROM teams WHERE id = $1 AND deleted_at IS NULL`, id).Scan(teamFields(&team)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, exception.EntityNotFoundException{Entity: "Team", ID: id}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if team.Players, err = repo.getPlayerIDs(id); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (repo *SQLTeamRepository) Create(team entity.Team) (*entity.Team, error) {
+	err := withSQLTransaction(repo.db, func(tx sqlExecutor) error {
+		args := append([]any{team.Name, team.Logo, team.Provider, jsonColumn{team.ExternalIDs}, team.SyncSchedule},
+			teamProfileValues(team)...)
+		if err := tx.QueryRow(`INSERT INTO teams (name, logo, provider, external_ids, sync_schedule, `+teamProfileColumns+`,
+			search_name, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, 1)
+			RETURNING id, version`, append(args, names.Normalize(team.Name))...).
+			Scan(&team.ID, &team.Version); err != nil {
+			return err
+		}
+		return insertTeamPlayers(tx, team.ID, team.Players)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (repo *SQLTeamRepository) Update(team entity.Team) (*entity.Team, error) {
+	err := withSQLTransaction(repo.db, func(tx sqlExecutor) error {
+		args := append([]any{team.Name, team.Logo, team.Provider, jsonColumn{team.ExternalIDs}, team.SyncSchedule},
+			teamProfileValues(team)...)
+		result, err := tx.Exec(`UPDATE teams SET name = $1, logo = $2, provider = $3, external_ids = $4, sync_schedule = $5,
+			short_name = $6, alternate_names = $7, formed_year = $8, sport = $9, league = $10, country = $11, stadium = $12,
+			stadium_capacity = $13, colors = $14, badge = $15, banner = $16, jersey = $17, fanart = $18, website = $19,
+			social_links = $20, description = $21, edited_fields = $22, search_name = $23, version = version + 1
+			WHERE id = $24 AND version = $25 AND deleted_at IS NULL`, append(args, names.Normalize(team.Name), team.ID, team.Version)...)
+		if err != nil {
+			return err
+		}
+		if err := expectVersion(tx, result, "teams", "Team", team.ID, team.Version); err != nil {
+			return err
+		}
+		// Replace the roster with the players of the updated team
+		if _, err := tx.Exec(`DELETE FROM team_players WHERE team_id = $1`, team.ID); err != nil {
+			return err
+		}
+		return insertTeamPlayers(tx, team.ID, team.Players)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The outcome of the last sync is only changed by RecordSync
+	return repo.GetByID(team.ID)
+}
+
+func (repo *SQLTeamRepository) Delete(id int) error {
+	result, err := repo.db.Exec(`UPDATE teams SET deleted_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL`,
+		time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "Team", id)
+}
+
+func (repo *SQLTeamRepository) GetPlayersByID(id int) ([]entity.Player, error) {
+	var exists bool
+	if err := repo.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM teams WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, exception.EntityNotFoundException{Entity: "Team", ID: id}
+	}
+
+	playerIDs, err := repo.getPlayerIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	var players []entity.Player
+	for _, playerID := range playerIDs {
+		players = append(players, entity.Player{ID: playerID})
+	}
+	return players, nil
+}
+
+func (repo *SQLTeamRepository) GetByPlayerID(playerID int) ([]entity.Team, error) {
+	rows, err := repo.db.Query(`SELECT DISTINCT team_players.team_id FROM team_players
+		JOIN teams ON teams.id = team_players.team_id
+		WHERE team_players.player_id = $1 AND teams.deleted_at IS NULL ORDER BY team_players.team_id`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teamIDs []int
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var teams []entity.Team
+	for _, teamID := range teamIDs {
+		team, err := repo.GetByID(teamID)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *team)
+	}
+	return teams, nil
+}
+
+func (repo *SQLTeamRepository) GetDeleted() ([]entity.Team, error) {
+	rows, err := repo.db.Query(`SELECT ` + teamColumns + `, deleted_at FROM teams WHERE deleted_at IS NOT NULL ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []entity.Team
+	for rows.Next() {
+		var team entity.Team
+		var deletedAt sql.NullTime
+		if err := rows.Scan(append(teamFields(&team), &deletedAt)...); err != nil {
+			return nil, err
+		}
+		team.DeletedAt = &deletedAt.Time
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range teams {
+		if teams[i].Players, err = repo.getPlayerIDs(teams[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return teams, nil
+}
+
+func (repo *SQLTeamRepository) Restore(id int) (*entity.Team, error) {
+	result, err := repo.db.Exec(`UPDATE teams SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectAffected(result, "Team", id); err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+func (repo *SQLTeamRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	// The rosters of the purged teams are deleted by the foreign key cascade
+	return queryIDs(repo.db, `DELETE FROM teams WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING id`, deletedBefore)
+}
+
+func (repo *SQLTeamRepository) RecordSync(id int, syncedAt time.Time, status string) error {
+	result, err := repo.db.Exec(`UPDATE teams SET last_synced_at = $1, last_sync_status = $2 WHERE id = $3 AND deleted_at IS NULL`,
+		syncedAt, status, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, "Team", id)
+}
+
+// backfillTeamSearchNames stores the normalized names the teams are searched by, for the teams written before the
+// search column existed.
+func backfillTeamSearchNames(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, name FROM teams`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var teams []entity.Team
+	for rows.Next() {
+		var team entity.Team
+		if err := rows.Scan(&team.ID, &team.Name); err != nil {
+			return err
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, team := range teams {
+		if _, err := tx.Exec(`UPDATE teams SET search_name = $1 WHERE id = $2`, names.Normalize(team.Name), team.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryTeams runs a query selecting the teamColumns of teams, and loads their rosters.
+func (repo *SQLTeamRepository) queryTeams(query string, args ...any) ([]entity.Team, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []entity.Team{}
+	for rows.Next() {
+		var team entity.Team
+		if err := rows.Scan(teamFields(&team)...); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range teams {
+		if teams[i].Players, err = repo.getPlayerIDs(teams[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return teams, nil
+}
+
+// getPlayerIDs returns the roster of a team in the order the players were added.
+func (repo *SQLTeamRepository) getPlayerIDs(teamID int) ([]int, error) {
+	rows, err := repo.db.Query(`SELECT player_id FROM team_players WHERE team_id = $1 ORDER BY position`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var playerIDs []int
+	for rows.Next() {
+		var playerID int
+		if err := rows.Scan(&playerID); err != nil {
+			return nil, err
+		}
+		playerIDs = append(playerIDs, playerID)
+	}
+	return playerIDs, rows.Err()
+}
+
+func insertTeamPlayers(tx sqlExecutor, teamID int, playerIDs []int) error {
+	for position, playerID := range playerIDs {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM players WHERE id = $1 AND deleted_at IS NULL)`, playerID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return exception.ReferentialIntegrityException{Entity: "Team", ID: teamID, Reason: fmt.Sprintf("references missing player %d", playerID)}
+		}
+		if _, err := tx.Exec(`INSERT INTO team_players (team_id, player_id, position) VALUES ($1, $2, $3)`,
+			teamID, playerID, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
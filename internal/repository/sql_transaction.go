@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// sqlExecutor is implemented by both *sql.DB and *sql.Tx, so the SQL repositories can run inside a transaction.
+type sqlExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type SQLTransactionManager struct {
+	db *sql.DB
+}
+
+func NewSQLTransactionManager(db *sql.DB) *SQLTransactionManager {
+	return &SQLTransactionManager{
+		db: db,
+	}
+}
+
+// NewSQLRepository creates the SQL repositories sharing a transaction manager.
+func NewSQLRepository(db *sql.DB) Repository {
+	return Repository{
+		Team:         NewSQLTeamRepository(db),
+		Player:       NewSQLPlayerRepository(db),
+		History:      NewSQLHistoryRepository(db),
+		Transactions: NewSQLTransactionManager(db),
+	}
+}
+
+func (m *SQLTransactionManager) Begin() (Transaction, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTransaction{tx: tx}, nil
+}
+
+type sqlTransaction struct {
+	tx *sql.Tx
+}
+
+func (t *sqlTransaction) Team() TeamRepository {
+	return NewSQLTeamRepository(t.tx)
+}
+
+func (t *sqlTransaction) Player() PlayerRepository {
+	return NewSQLPlayerRepository(t.tx)
+}
+
+func (t *sqlTransaction) History() HistoryRepository {
+	return NewSQLHistoryRepository(t.tx)
+}
+
+func (t *sqlTransaction) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *sqlTransaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// withSQLTransaction runs fn in a new transaction, or directly when the executor already is one.
+func withSQLTransaction(executor sqlExecutor, fn func(tx sqlExecutor) error) error {
+	db, ok := executor.(*sql.DB)
+	if !ok {
+		return fn(executor)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
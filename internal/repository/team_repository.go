@@ -1,13 +1,24 @@
 package repository
 
 import (
+	"fmt"
+	"maps"
 	"scoreplay/internal/entity"
 	"scoreplay/internal/exception"
+	"scoreplay/internal/names"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type InMemoryTeamRepository struct {
-	teams  map[int]entity.Team
-	autoID int
+	mu      sync.RWMutex
+	teams   map[int]entity.Team
+	autoID  int
+	journal *InMemoryJournal
+	players *InMemoryPlayerRepository
 }
 
 func NewInMemoryTeamRepository() *InMemoryTeamRepository {
@@ -18,50 +29,246 @@ func NewInMemoryTeamRepository() *InMemoryTeamRepository {
 }
 
 func (repo *InMemoryTeamRepository) GetAll() ([]entity.Team, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getAll()
+}
+
+func (repo *InMemoryTeamRepository) Find(query TeamQuery) (*TeamPage, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.find(query)
+}
+
+func (repo *InMemoryTeamRepository) GetByID(id int) (*entity.Team, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getByID(id)
+}
+
+func (repo *InMemoryTeamRepository) Create(team entity.Team) (*entity.Team, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	unlockPlayers := repo.readLockPlayers()
+	defer unlockPlayers()
+
+	return repo.create(team, repo)
+}
+
+func (repo *InMemoryTeamRepository) Update(team entity.Team) (*entity.Team, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	unlockPlayers := repo.readLockPlayers()
+	defer unlockPlayers()
+
+	return repo.update(team, repo)
+}
+
+func (repo *InMemoryTeamRepository) Delete(id int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.delete(id, repo)
+}
+
+func (repo *InMemoryTeamRepository) GetPlayersByID(id int) ([]entity.Player, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getPlayersByID(id)
+}
+
+func (repo *InMemoryTeamRepository) GetByPlayerID(playerID int) ([]entity.Team, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getByPlayerID(playerID)
+}
+
+func (repo *InMemoryTeamRepository) GetDeleted() ([]entity.Team, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getDeleted()
+}
+
+func (repo *InMemoryTeamRepository) Restore(id int) (*entity.Team, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.restore(id, repo)
+}
+
+func (repo *InMemoryTeamRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.purge(deletedBefore, repo)
+}
+
+func (repo *InMemoryTeamRepository) RecordSync(id int, syncedAt time.Time, status string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.recordSync(id, syncedAt, status, repo)
+}
+
+func (repo *InMemoryTeamRepository) getAll() ([]entity.Team, error) {
 	var teams []entity.Team
 	for _, team := range repo.teams {
-		teams = append(teams, team)
+		if team.DeletedAt == nil {
+			teams = append(teams, cloneTeam(team))
+		}
 	}
 	return teams, nil
 }
 
-func (repo *InMemoryTeamRepository) GetByID(id int) (*entity.Team, error) {
+func (repo *InMemoryTeamRepository) find(query TeamQuery) (*TeamPage, error) {
+	teams := []entity.Team{}
+	for _, team := range repo.teams {
+		if team.DeletedAt == nil && teamMatches(team, query.Filter) {
+			teams = append(teams, cloneTeam(team))
+		}
+	}
+	selected, nextCursor, err := paginate(teams, query.Sort, query.Page, teamSortableFields)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamPage{Teams: selected, Total: len(teams), NextCursor: nextCursor}, nil
+}
+
+func (repo *InMemoryTeamRepository) getByID(id int) (*entity.Team, error) {
 	team, exists := repo.teams[id]
-	if !exists {
+	if !exists || team.DeletedAt != nil {
 		return nil, exception.EntityNotFoundException{Entity: "Team", ID: id}
 	}
+	team = cloneTeam(team)
 	return &team, nil
 }
 
-func (repo *InMemoryTeamRepository) Create(team entity.Team) (*entity.Team, error) {
+func (repo *InMemoryTeamRepository) create(team entity.Team, recorder changeRecorder) (*entity.Team, error) {
+	if err := repo.checkRoster(team); err != nil {
+		return nil, err
+	}
+	team.ID = repo.autoID + 1
+	team.Version = 1
+	undo := func() { delete(repo.teams, team.ID) }
+	if err := recorder.record(journalRecord{Op: opPutTeam, Team: &team}, undo); err != nil {
+		return nil, err
+	}
 	repo.autoID++
-	team.ID = repo.autoID
-	repo.teams[team.ID] = team
+	repo.teams[team.ID] = cloneTeam(team)
 	return &team, nil
 }
 
-func (repo *InMemoryTeamRepository) Update(team entity.Team) (*entity.Team, error) {
-	_, exists := repo.teams[team.ID]
-	if !exists {
+func (repo *InMemoryTeamRepository) update(team entity.Team, recorder changeRecorder) (*entity.Team, error) {
+	previous, exists := repo.teams[team.ID]
+	if !exists || previous.DeletedAt != nil {
 		return nil, exception.EntityNotFoundException{Entity: "Team", ID: team.ID}
 	}
-	repo.teams[team.ID] = team
+	if previous.Version != team.Version {
+		return nil, exception.VersionConflictException{Entity: "Team", ID: team.ID, ExpectedVersion: team.Version, ActualVersion: previous.Version}
+	}
+	if err := repo.checkRoster(team); err != nil {
+		return nil, err
+	}
+	// The outcome of the last sync is only changed by RecordSync
+	team.LastSyncedAt = previous.LastSyncedAt
+	team.LastSyncStatus = previous.LastSyncStatus
+	team.Version++
+	undo := func() { repo.teams[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutTeam, Team: &team}, undo); err != nil {
+		return nil, err
+	}
+	repo.teams[team.ID] = cloneTeam(team)
 	return &team, nil
 }
 
-func (repo *InMemoryTeamRepository) Delete(id int) error {
-	_, exists := repo.teams[id]
-	if !exists {
+func (repo *InMemoryTeamRepository) delete(id int, recorder changeRecorder) error {
+	previous, exists := repo.teams[id]
+	if !exists || previous.DeletedAt != nil {
 		return exception.EntityNotFoundException{Entity: "Team", ID: id}
 	}
-	delete(repo.teams, id)
+	team := cloneTeam(previous)
+	deletedAt := time.Now().UTC()
+	team.DeletedAt = &deletedAt
+	team.Version++
+	undo := func() { repo.teams[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutTeam, Team: &team}, undo); err != nil {
+		return err
+	}
+	repo.teams[id] = team
 	return nil
 }
 
-func (repo *InMemoryTeamRepository) GetPlayersByID(id int) ([]entity.Player, error) {
+func (repo *InMemoryTeamRepository) recordSync(id int, syncedAt time.Time, status string, recorder changeRecorder) error {
+	previous, exists := repo.teams[id]
+	if !exists || previous.DeletedAt != nil {
+		return exception.EntityNotFoundException{Entity: "Team", ID: id}
+	}
+	team := cloneTeam(previous)
+	team.LastSyncedAt = &syncedAt
+	team.LastSyncStatus = status
+	undo := func() { repo.teams[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutTeam, Team: &team}, undo); err != nil {
+		return err
+	}
+	repo.teams[id] = team
+	return nil
+}
+
+func (repo *InMemoryTeamRepository) getDeleted() ([]entity.Team, error) {
+	var teams []entity.Team
+	for _, team := range repo.teams {
+		if team.DeletedAt != nil {
+			teams = append(teams, cloneTeam(team))
+		}
+	}
+	return teams, nil
+}
+
+func (repo *InMemoryTeamRepository) restore(id int, recorder changeRecorder) (*entity.Team, error) {
+	previous, exists := repo.teams[id]
+	if !exists || previous.DeletedAt == nil {
+		return nil, exception.EntityNotFoundException{Entity: "Team", ID: id}
+	}
+	team := cloneTeam(previous)
+	team.DeletedAt = nil
+	team.Version++
+	undo := func() { repo.teams[previous.ID] = previous }
+	if err := recorder.record(journalRecord{Op: opPutTeam, Team: &team}, undo); err != nil {
+		return nil, err
+	}
+	repo.teams[id] = cloneTeam(team)
+	return &team, nil
+}
+
+func (repo *InMemoryTeamRepository) purge(deletedBefore time.Time, recorder changeRecorder) ([]int, error) {
+	var ids []int
+	for _, team := range repo.teams {
+		if team.DeletedAt != nil && team.DeletedAt.Before(deletedBefore) {
+			ids = append(ids, team.ID)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		previous := repo.teams[id]
+		undo := func() { repo.teams[previous.ID] = previous }
+		if err := recorder.record(journalRecord{Op: opDeleteTeam, ID: id}, undo); err != nil {
+			return nil, err
+		}
+		delete(repo.teams, id)
+	}
+	return ids, nil
+}
+
+func (repo *InMemoryTeamRepository) getPlayersByID(id int) ([]entity.Player, error) {
 	var players []entity.Player
 	team, exists := repo.teams[id]
-	if !exists {
+	if !exists || team.DeletedAt != nil {
 		return nil, exception.EntityNotFoundException{Entity: "Team", ID: id}
 	}
 	for _, playerID := range team.Players {
@@ -69,3 +276,76 @@ func (repo *InMemoryTeamRepository) GetPlayersByID(id int) ([]entity.Player, err
 	}
 	return players, nil
 }
+
+func (repo *InMemoryTeamRepository) getByPlayerID(playerID int) ([]entity.Team, error) {
+	var teams []entity.Team
+	for _, team := range repo.teams {
+		if team.DeletedAt != nil {
+			continue
+		}
+		for _, id := range team.Players {
+			if id == playerID {
+				teams = append(teams, cloneTeam(team))
+				break
+			}
+		}
+	}
+	return teams, nil
+}
+
+// checkRoster rejects a team referencing players that do not exist or are deleted, when the repository is linked to the players.
+func (repo *InMemoryTeamRepository) checkRoster(team entity.Team) error {
+	if repo.players == nil {
+		return nil
+	}
+	for _, playerID := range team.Players {
+		if player, exists := repo.players.players[playerID]; !exists || player.DeletedAt != nil {
+			return exception.ReferentialIntegrityException{Entity: "Team", ID: team.ID, Reason: fmt.Sprintf("references missing player %d", playerID)}
+		}
+	}
+	return nil
+}
+
+// readLockPlayers locks the linked players for reading, always after the teams like transactions do.
+func (repo *InMemoryTeamRepository) readLockPlayers() func() {
+	if repo.players == nil {
+		return func() {}
+	}
+	repo.players.mu.RLock()
+	return repo.players.mu.RUnlock
+}
+
+// record writes the change to the journal, if any, before it is applied.
+func (repo *InMemoryTeamRepository) record(record journalRecord, _ func()) error {
+	if repo.journal == nil {
+		return nil
+	}
+	return repo.journal.append(record)
+}
+
+func teamMatches(team entity.Team, filter TeamFilter) bool {
+	if filter.Name != "" && !strings.Contains(names.Normalize(team.Name), names.Normalize(filter.Name)) {
+		return false
+	}
+	if filter.HasLogo != nil && (team.Logo != "") != *filter.HasLogo {
+		return false
+	}
+	if filter.PlayerID != 0 && !slices.Contains(team.Players, filter.PlayerID) {
+		return false
+	}
+	return true
+}
+
+// cloneTeam copies the roster and the external identifiers so callers never share the ones stored in the map.
+func cloneTeam(team entity.Team) entity.Team {
+	if team.Players != nil {
+		team.Players = append([]int(nil), team.Players...)
+	}
+	team.AlternateNames = slices.Clone(team.AlternateNames)
+	team.Colors = slices.Clone(team.Colors)
+	team.Fanart = slices.Clone(team.Fanart)
+	team.SocialLinks = maps.Clone(team.SocialLinks)
+	team.EditedFields = slices.Clone(team.EditedFields)
+	team.ExternalIDs = maps.Clone(team.ExternalIDs)
+	return team
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"errors"
+	"scoreplay/internal/entity"
+	"time"
+)
+
+var errTransactionDone = errors.New("transaction has already been committed or rolled back")
+
+// changeRecorder receives every change of an in-memory repository before it is applied,
+// together with the function reverting it.
+type changeRecorder interface {
+	record(record journalRecord, undo func()) error
+}
+
+// InMemoryTransactionManager begins transactions spanning the in-memory team, player and history repositories.
+// A transaction holds the write lock of every repository until it is committed or rolled back.
+type InMemoryTransactionManager struct {
+	teams   *InMemoryTeamRepository
+	players *InMemoryPlayerRepository
+	history *InMemoryHistoryRepository
+}
+
+func NewInMemoryTransactionManager(teams *InMemoryTeamRepository, players *InMemoryPlayerRepository,
+	history *InMemoryHistoryRepository) *InMemoryTransactionManager {
+	return &InMemoryTransactionManager{
+		teams:   teams,
+		players: players,
+		history: history,
+	}
+}
+
+// NewInMemoryRepository creates empty in-memory repositories sharing a transaction manager.
+func NewInMemoryRepository() Repository {
+	return newInMemoryRepository(NewInMemoryTeamRepository(), NewInMemoryPlayerRepository(), NewInMemoryHistoryRepository())
+}
+
+func newInMemoryRepository(teams *InMemoryTeamRepository, players *InMemoryPlayerRepository, history *InMemoryHistoryRepository) Repository {
+	// Link the repositories so team rosters can only reference existing players
+	teams.players = players
+	players.teams = teams
+	return Repository{
+		Team:         teams,
+		Player:       players,
+		History:      history,
+		Transactions: NewInMemoryTransactionManager(teams, players, history),
+	}
+}
+
+func (m *InMemoryTransactionManager) Begin() (Transaction, error) {
+	// Always lock teams, players and then history, like the journal compaction does
+	m.teams.mu.Lock()
+	m.players.mu.Lock()
+	m.history.mu.Lock()
+	return &inMemoryTransaction{
+		manager:      m,
+		teamAutoID:   m.teams.autoID,
+		playerAutoID: m.players.autoID,
+	}, nil
+}
+
+type inMemoryTransaction struct {
+	manager      *InMemoryTransactionManager
+	records      []journalRecord
+	undo         []func()
+	teamAutoID   int
+	playerAutoID int
+	done         bool
+}
+
+func (tx *inMemoryTransaction) Team() TeamRepository {
+	return &inMemoryTransactionTeamRepository{tx: tx}
+}
+
+func (tx *inMemoryTransaction) Player() PlayerRepository {
+	return &inMemoryTransactionPlayerRepository{tx: tx}
+}
+
+func (tx *inMemoryTransaction) History() HistoryRepository {
+	return &inMemoryTransactionHistoryRepository{tx: tx}
+}
+
+// Commit writes the changes of the transaction to the journal as a single frame, so they are
+// replayed together or not at all.
+func (tx *inMemoryTransaction) Commit() error {
+	if tx.done {
+		return errTransactionDone
+	}
+	if journal := tx.manager.teams.journal; journal != nil && len(tx.records) > 0 {
+		if err := journal.append(tx.records...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.finish()
+	return nil
+}
+
+func (tx *inMemoryTransaction) Rollback() error {
+	if tx.done {
+		return nil
+	}
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.manager.teams.autoID = tx.teamAutoID
+	tx.manager.players.autoID = tx.playerAutoID
+	tx.finish()
+	return nil
+}
+
+// record buffers the change until commit, the journal is only written once the transaction succeeds.
+func (tx *inMemoryTransaction) record(record journalRecord, undo func()) error {
+	if tx.done {
+		return errTransactionDone
+	}
+	tx.records = append(tx.records, record)
+	tx.undo = append(tx.undo, undo)
+	return nil
+}
+
+func (tx *inMemoryTransaction) finish() {
+	tx.done = true
+	tx.manager.history.mu.Unlock()
+	tx.manager.players.mu.Unlock()
+	tx.manager.teams.mu.Unlock()
+}
+
+type inMemoryTransactionTeamRepository struct {
+	tx *inMemoryTransaction
+}
+
+func (repo *inMemoryTransactionTeamRepository) GetAll() ([]entity.Team, error) {
+	return repo.tx.manager.teams.getAll()
+}
+
+func (repo *inMemoryTransactionTeamRepository) Find(query TeamQuery) (*TeamPage, error) {
+	return repo.tx.manager.teams.find(query)
+}
+
+func (repo *inMemoryTransactionTeamRepository) GetByID(id int) (*entity.Team, error) {
+	return repo.tx.manager.teams.getByID(id)
+}
+
+func (repo *inMemoryTransactionTeamRepository) Create(team entity.Team) (*entity.Team, error) {
+	return repo.tx.manager.teams.create(team, repo.tx)
+}
+
+func (repo *inMemoryTransactionTeamRepository) Update(team entity.Team) (*entity.Team, error) {
+	return repo.tx.manager.teams.update(team, repo.tx)
+}
+
+func (repo *inMemoryTransactionTeamRepository) Delete(id int) error {
+	return repo.tx.manager.teams.delete(id, repo.tx)
+}
+
+func (repo *inMemoryTransactionTeamRepository) GetPlayersByID(id int) ([]entity.Player, error) {
+	return repo.tx.manager.teams.getPlayersByID(id)
+}
+
+func (repo *inMemoryTransactionTeamRepository) GetByPlayerID(playerID int) ([]entity.Team, error) {
+	return repo.tx.manager.teams.getByPlayerID(playerID)
+}
+
+func (repo *inMemoryTransactionTeamRepository) GetDeleted() ([]entity.Team, error) {
+	return repo.tx.manager.teams.getDeleted()
+}
+
+func (repo *inMemoryTransactionTeamRepository) Restore(id int) (*entity.Team, error) {
+	return repo.tx.manager.teams.restore(id, repo.tx)
+}
+
+func (repo *inMemoryTransactionTeamRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	return repo.tx.manager.teams.purge(deletedBefore, repo.tx)
+}
+
+func (repo *inMemoryTransactionTeamRepository) RecordSync(id int, syncedAt time.Time, status string) error {
+	return repo.tx.manager.teams.recordSync(id, syncedAt, status, repo.tx)
+}
+
+type inMemoryTransactionPlayerRepository struct {
+	tx *inMemoryTransaction
+}
+
+func (repo *inMemoryTransactionPlayerRepository) GetAll() ([]entity.Player, error) {
+	return repo.tx.manager.players.getAll()
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Find(query PlayerQuery) (*PlayerPage, error) {
+	return repo.tx.manager.players.find(query)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) GetByID(id int) (*entity.Player, error) {
+	return repo.tx.manager.players.getByID(id)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Create(player entity.Player) (*entity.Player, error) {
+	return repo.tx.manager.players.create(player, repo.tx)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Update(player entity.Player) (*entity.Player, error) {
+	return repo.tx.manager.players.update(player, repo.tx)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Delete(id int) error {
+	return repo.tx.manager.players.delete(id, repo.tx)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Merge(id int, intoID int) error {
+	return repo.tx.manager.players.merge(id, intoID, repo.tx)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) GetMergedInto(id int) (int, error) {
+	return repo.tx.manager.players.getMergedInto(id)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) GetDeleted() ([]entity.Player, error) {
+	return repo.tx.manager.players.getDeleted()
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Restore(id int) (*entity.Player, error) {
+	return repo.tx.manager.players.restore(id, repo.tx)
+}
+
+func (repo *inMemoryTransactionPlayerRepository) Purge(deletedBefore time.Time) ([]int, error) {
+	return repo.tx.manager.players.purge(deletedBefore, repo.tx)
+}
+
+type inMemoryTransactionHistoryRepository struct {
+	tx *inMemoryTransaction
+}
+
+func (repo *inMemoryTransactionHistoryRepository) Create(entry entity.HistoryEntry) (*entity.HistoryEntry, error) {
+	return repo.tx.manager.history.create(entry, repo.tx)
+}
+
+func (repo *inMemoryTransactionHistoryRepository) GetByEntity(entityType string, entityID int, offset int, limit int) ([]entity.HistoryEntry, int, error) {
+	return repo.tx.manager.history.getByEntity(entityType, entityID, offset, limit)
+}
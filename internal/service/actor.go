@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"scoreplay/internal/repository"
+)
+
+type contextKey int
+
+const (
+	actorContextKey contextKey = iota
+	sourceContextKey
+	forcedDeparturesContextKey
+)
+
+// DefaultActor is recorded in the history when the context carries no actor.
+const DefaultActor = "anonymous"
+
+// WithActor returns a context whose changes are recorded in the history on behalf of the actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// withSource returns a context whose changes are recorded in the history as coming from the source.
+func withSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceContextKey, source)
+}
+
+// auditTransaction records the changes made through the transaction on behalf of the actor of the context.
+func auditTransaction(ctx context.Context, tx repository.Transaction) repository.Transaction {
+	auditor := repository.Auditor{Actor: DefaultActor, Source: repository.HistorySourceAPI}
+	if actor, ok := ctx.Value(actorContextKey).(string); ok && actor != "" {
+		auditor.Actor = actor
+	}
+	if source, ok := ctx.Value(sourceContextKey).(string); ok {
+		auditor.Source = source
+	}
+	return repository.NewAuditedTransaction(tx, auditor)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"scoreplay/internal/exception"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyncScheduleOff disables the automatic syncs of a team.
+const SyncScheduleOff = "off"
+
+// CronSchedule is a cron schedule of five fields, minute, hour, day of month, month and day of week, each a "*", a value,
+// a range or a comma separated list of them, optionally stepped with "/". The macros @hourly, @daily, @weekly and
+// @monthly are supported too. Like cron, a day matches when either of the restricted day fields matches.
+type CronSchedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	anyDay      bool
+	anyWeekday  bool
+}
+
+var cronMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// cronFieldBounds are the minimum and maximum values of the fields, Sunday being 0 or 7 in the day of week.
+var cronFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseCronSchedule parses a cron schedule, failing with an InvalidArgumentException.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, exists := cronMacros[spec]; exists {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, invalidCronSchedule(spec, "expected 5 fields")
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return nil, invalidCronSchedule(spec, err.Error())
+		}
+		sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &CronSchedule{
+		minutes:     sets[0],
+		hours:       sets[1],
+		daysOfMonth: sets[2],
+		months:      sets[3],
+		daysOfWeek:  sets[4],
+		anyDay:      fields[2] == "*",
+		anyWeekday:  fields[4] == "*",
+	}, nil
+}
+
+// Matches tells whether the schedule fires at the minute of the time.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	if s.minutes&(1<<t.Minute()) == 0 || s.hours&(1<<t.Hour()) == 0 || s.months&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dayMatches := s.daysOfMonth&(1<<t.Day()) != 0
+	weekdayMatches := s.daysOfWeek&(1<<int(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekdayMatches
+	case s.anyWeekday:
+		return dayMatches
+	default:
+		return dayMatches || weekdayMatches
+	}
+}
+
+// parseCronField returns the set of values of a field as a bit mask.
+func parseCronField(field string, minimum int, maximum int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		valueRange, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		low, high := minimum, maximum
+		if valueRange != "*" {
+			lowText, highText, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if low, err = strconv.Atoi(lowText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", lowText)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highText); err != nil {
+					return 0, fmt.Errorf("invalid value %q", highText)
+				}
+			} else if stepped {
+				// "5/15" steps from the value to the maximum
+				high = maximum
+			}
+		}
+		if low < minimum || high > maximum || low > high {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, minimum, maximum)
+		}
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+	return set, nil
+}
+
+func invalidCronSchedule(spec string, reason string) error {
+	return exception.InvalidArgumentException{Argument: "sync_schedule", Reason: fmt.Sprintf("%q: %s", spec, reason)}
+}
+
+// validateSyncSchedule checks the sync schedule of a team, empty and "off" being valid.
+func validateSyncSchedule(spec string) error {
+	if spec == "" || spec == SyncScheduleOff {
+		return nil
+	}
+	_, err := ParseCronSchedule(spec)
+	return err
+}
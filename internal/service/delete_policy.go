@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeletePolicy decides what happens to the roster references when a team or a player is deleted.
+type DeletePolicy string
+
+const (
+	// DeletePolicyRestrict refuses to delete a team with players, or a player on a team roster.
+	DeletePolicyRestrict DeletePolicy = "restrict"
+	// DeletePolicyCascade deletes the players of a deleted team that are on no other roster,
+	// and removes a deleted player from every roster.
+	DeletePolicyCascade DeletePolicy = "cascade"
+	// DeletePolicyDetach keeps the players of a deleted team, and removes a deleted player from every roster.
+	DeletePolicyDetach DeletePolicy = "detach"
+)
+
+// ParseDeletePolicy parses a policy name, an empty name defaults to DeletePolicyDetach.
+func ParseDeletePolicy(name string) (DeletePolicy, error) {
+	switch policy := DeletePolicy(strings.ToLower(name)); policy {
+	case "":
+		return DeletePolicyDetach, nil
+	case DeletePolicyRestrict, DeletePolicyCascade, DeletePolicyDetach:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown delete policy %q", name)
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DeparturePolicy decides what a sync does with the players of a roster that left the provider squad.
+type DeparturePolicy string
+
+const (
+	// DeparturePolicyDetach removes a departed player from the roster, and keeps it as a free agent.
+	DeparturePolicyDetach DeparturePolicy = "detach"
+	// DeparturePolicyArchive removes a departed player from the roster, and deletes it when it is on no other roster.
+	DeparturePolicyArchive DeparturePolicy = "archive"
+	// DeparturePolicyKeep leaves a departed player on the roster.
+	DeparturePolicyKeep DeparturePolicy = "keep"
+)
+
+// ParseDeparturePolicy parses a policy name, an empty name defaults to DeparturePolicyDetach.
+func ParseDeparturePolicy(name string) (DeparturePolicy, error) {
+	switch policy := DeparturePolicy(strings.ToLower(name)); policy {
+	case "":
+		return DeparturePolicyDetach, nil
+	case DeparturePolicyDetach, DeparturePolicyArchive, DeparturePolicyKeep:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown departure policy %q", name)
+	}
+}
+
+// WithForcedDepartures returns a context whose syncs remove the players that left the provider squad even when the
+// squad came back empty, or when more players left than the maximum share of the roster.
+func WithForcedDepartures(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcedDeparturesContextKey, true)
+}
+
+// forcedDepartures tells whether the syncs of the context skip the departure guard.
+func forcedDepartures(ctx context.Context) bool {
+	forced, _ := ctx.Value(forcedDeparturesContextKey).(bool)
+	return forced
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"math"
+	"scoreplay/internal/dto"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/mapper"
+	"scoreplay/internal/names"
+	"scoreplay/internal/repository"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// defaultDuplicateMinScore is the score from which two players are suspected duplicates, unless the query sets one.
+const defaultDuplicateMinScore = 0.8
+
+// DefaultDuplicateService finds the players suspected to be the same person, scoring the pairs of players on the
+// similarity of their names, their dates of birth and their IDs at the providers.
+type DefaultDuplicateService struct {
+	repository repository.Repository
+	mapper     mapper.PlayerMapper
+}
+
+func NewDefaultDuplicateService(repository repository.Repository, mapper mapper.PlayerMapper) *DefaultDuplicateService {
+	return &DefaultDuplicateService{
+		repository: repository,
+		mapper:     mapper,
+	}
+}
+
+// GetDuplicates groups the players linked by pairs scoring at least the minimum score into clusters, highest score first.
+func (s *DefaultDuplicateService) GetDuplicates(ctx context.Context, query dto.DuplicateQueryDTO) (*dto.DuplicateClustersDTO, error) {
+	minScore := defaultDuplicateMinScore
+	if query.MinScore != nil {
+		minScore = *query.MinScore
+	}
+	var roster []int
+	if query.TeamID != 0 {
+		team, err := s.repository.Team.GetByID(query.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if len(team.Players) == 0 {
+			return &dto.DuplicateClustersDTO{Clusters: []dto.DuplicateClusterDTO{}}, nil
+		}
+		roster = team.Players
+	}
+	players, err := s.repository.Player.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+
+	// Only the players sharing a word of their names, their date of birth or an ID at a provider are compared
+	blocks := make(map[string][]int)
+	for i, player := range players {
+		for _, key := range duplicateBlockingKeys(player) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+	compared := make(map[[2]int]struct{})
+	var candidates []dto.DuplicateCandidateDTO
+	for _, block := range blocks {
+		for i, first := range block {
+			for _, second := range block[i+1:] {
+				pair := [2]int{first, second}
+				if _, done := compared[pair]; done {
+					continue
+				}
+				compared[pair] = struct{}{}
+				player, duplicate := players[first], players[second]
+				if query.TeamID != 0 && !slices.Contains(roster, player.ID) && !slices.Contains(roster, duplicate.ID) {
+					continue
+				}
+				if candidate, suspected := scoreDuplicate(player, duplicate); suspected && candidate.Score >= minScore {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+	}
+	return s.clusterDuplicates(players, candidates), nil
+}
+
+// clusterDuplicates groups the players linked by candidate pairs, transitively.
+func (s *DefaultDuplicateService) clusterDuplicates(players []entity.Player, candidates []dto.DuplicateCandidateDTO) *dto.DuplicateClustersDTO {
+	parents := make(map[int]int)
+	var root func(id int) int
+	root = func(id int) int {
+		parent, exists := parents[id]
+		if !exists || parent == id {
+			return id
+		}
+		parents[id] = root(parent)
+		return parents[id]
+	}
+	for _, candidate := range candidates {
+		first, second := root(candidate.PlayerID), root(candidate.DuplicateID)
+		parents[first], parents[second] = first, second
+		parents[max(first, second)] = min(first, second)
+	}
+
+	clusters := make(map[int]*dto.DuplicateClusterDTO)
+	for _, candidate := range candidates {
+		cluster, exists := clusters[root(candidate.PlayerID)]
+		if !exists {
+			cluster = &dto.DuplicateClusterDTO{}
+			clusters[root(candidate.PlayerID)] = cluster
+		}
+		cluster.Score = max(cluster.Score, candidate.Score)
+		cluster.Candidates = append(cluster.Candidates, candidate)
+	}
+	for _, player := range players {
+		if _, linked := parents[player.ID]; linked {
+			cluster := clusters[root(player.ID)]
+			cluster.Players = append(cluster.Players, *s.mapper.MapToPlayerDTO(player))
+		}
+	}
+
+	result := &dto.DuplicateClustersDTO{Clusters: []dto.DuplicateClusterDTO{}}
+	for _, cluster := range clusters {
+		sort.Slice(cluster.Candidates, func(i, j int) bool {
+			a, b := cluster.Candidates[i], cluster.Candidates[j]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.PlayerID < b.PlayerID || a.PlayerID == b.PlayerID && a.DuplicateID < b.DuplicateID
+		})
+		result.Clusters = append(result.Clusters, *cluster)
+	}
+	sort.Slice(result.Clusters, func(i, j int) bool {
+		a, b := result.Clusters[i], result.Clusters[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Players[0].ID < b.Players[0].ID
+	})
+	return result
+}
+
+// scoreDuplicate scores from 0 to 1 how likely two players are the same person. The score is the similarity of their
+// names, halfway closer to 1 when they were born the same day, and 1 when they have the same ID at a provider. Players
+// born on different days, or with different IDs at a provider, are different persons.
+func scoreDuplicate(player entity.Player, duplicate entity.Player) (dto.DuplicateCandidateDTO, bool) {
+	candidate := dto.DuplicateCandidateDTO{PlayerID: player.ID, DuplicateID: duplicate.ID}
+	for provider, externalID := range player.ExternalIDs {
+		switch duplicate.ExternalIDs[provider] {
+		case "":
+		case externalID:
+			candidate.SharedExternalIDs = append(candidate.SharedExternalIDs, provider)
+		default:
+			return candidate, false
+		}
+	}
+	sort.Strings(candidate.SharedExternalIDs)
+	if player.DateOfBirth != "" && duplicate.DateOfBirth != "" {
+		if player.DateOfBirth != duplicate.DateOfBirth {
+			return candidate, false
+		}
+		candidate.SameDateOfBirth = true
+	}
+
+	for _, name := range playerNames(player) {
+		for _, duplicateName := range playerNames(duplicate) {
+			candidate.NameSimilarity = max(candidate.NameSimilarity, names.Similarity(name, duplicateName))
+		}
+	}
+	candidate.Score = candidate.NameSimilarity
+	if candidate.SameDateOfBirth {
+		candidate.Score += (1 - candidate.Score) / 2
+	}
+	if len(candidate.SharedExternalIDs) > 0 {
+		candidate.Score = 1
+	}
+	candidate.NameSimilarity = roundScore(candidate.NameSimilarity)
+	candidate.Score = roundScore(candidate.Score)
+	return candidate, true
+}
+
+// playerNames returns the names a player is compared by.
+func playerNames(player entity.Player) []string {
+	var playerNames []string
+	for _, name := range []string{fullName(player), player.FirstName + " " + player.LastName, player.KnownAs, player.NativeName} {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(playerNames, name) {
+			playerNames = append(playerNames, name)
+		}
+	}
+	return playerNames
+}
+
+// duplicateBlockingKeys returns the keys of the players a player is compared to: the words of its names, its date of
+// birth and its IDs at the providers.
+func duplicateBlockingKeys(player entity.Player) []string {
+	var keys []string
+	for _, name := range playerNames(player) {
+		for _, word := range strings.Fields(names.Normalize(name)) {
+			if len(word) > 1 && !slices.Contains(keys, "name:"+word) {
+				keys = append(keys, "name:"+word)
+			}
+		}
+	}
+	if player.DateOfBirth != "" {
+		keys = append(keys, "born:"+player.DateOfBirth)
+	}
+	for provider, externalID := range player.ExternalIDs {
+		keys = append(keys, "id:"+provider+":"+externalID)
+	}
+	return keys
+}
+
+// roundScore rounds a score to two decimals.
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"scoreplay/internal/dto"
+	"scoreplay/internal/mapper"
+	"scoreplay/internal/repository"
+)
+
+// getHistory returns a page of the history of an entity, most recent first, paginated like the lists. An entity
+// without any history is checked with existed, so the history of an ID never given is not found rather than empty.
+func getHistory(history repository.HistoryRepository, historyMapper mapper.HistoryMapper,
+	entityType string, id int, offset int, limit int, existed func(id int) error) (*dto.HistoryPageDTO, error) {
+	page := pageFromQuery(dto.ListQueryDTO{Offset: offset, Limit: limit})
+
+	entries, total, err := history.GetByEntity(entityType, id, page.Offset, page.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		if err := existed(id); err != nil {
+			return nil, err
+		}
+	}
+	return &dto.HistoryPageDTO{
+		Entries: historyMapper.MapToHistoryEntryDTOList(entries),
+		Total:   total,
+		Offset:  page.Offset,
+		Limit:   page.Limit,
+	}, nil
+}
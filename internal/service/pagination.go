@@ -0,0 +1,24 @@
+package service
+
+import (
+	"scoreplay/internal/dto"
+	"scoreplay/internal/repository"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// pageFromQuery converts the pagination parameters of a list endpoint, applying the default and maximum limits.
+func pageFromQuery(query dto.ListQueryDTO) repository.Page {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	return repository.Page{
+		Offset: max(query.Offset, 0),
+		Limit:  min(limit, maxPageLimit),
+		Cursor: query.Cursor,
+	}
+}
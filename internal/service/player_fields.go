@@ -0,0 +1,153 @@
+package service
+
+import (
+	"fmt"
+	thirdpartydto "scoreplay/internal/dto/thirdparty"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/names"
+	"slices"
+)
+
+// syncedPlayerFields are the fields of the players the syncs update, unless they were edited through the API.
+var syncedPlayerFields = []string{"first_name", "last_name", "display_name", "known_as", "native_name", "profile_picture",
+	"position", "shirt_number", "nationality", "date_of_birth", "height", "weight", "preferred_foot", "status", "cutout",
+	"render"}
+
+// playerNameFields are the synced fields holding the name of a player, updated even when empty upstream, like the family
+// name of a mononym.
+var playerNameFields = []string{"first_name", "last_name", "display_name"}
+
+// playerProfile returns the values of the synced fields of a player, by field.
+func playerProfile(player entity.Player) map[string]any {
+	return map[string]any{
+		"first_name":      player.FirstName,
+		"last_name":       player.LastName,
+		"display_name":    player.DisplayName,
+		"known_as":        player.KnownAs,
+		"native_name":     player.NativeName,
+		"profile_picture": player.ProfilePicture,
+		"position":        player.Position,
+		"shirt_number":    player.ShirtNumber,
+		"nationality":     player.Nationality,
+		"date_of_birth":   player.DateOfBirth,
+		"height":          player.Height,
+		"weight":          player.Weight,
+		"preferred_foot":  player.PreferredFoot,
+		"status":          player.Status,
+		"cutout":          player.Cutout,
+		"render":          player.Render,
+	}
+}
+
+// providerPlayer returns a player returned by a provider as a player of the roster, not linked to the provider yet.
+func providerPlayer(player thirdpartydto.ProviderPlayerDto) entity.Player {
+	name := names.Parse(player.Name)
+	return entity.Player{
+		FirstName:      name.Given,
+		LastName:       name.Family,
+		DisplayName:    name.Display,
+		KnownAs:        name.KnownAs,
+		NativeName:     name.Native,
+		ProfilePicture: player.Thumbnail,
+		Position:       player.Position,
+		ShirtNumber:    player.ShirtNumber,
+		Nationality:    player.Nationality,
+		DateOfBirth:    player.DateOfBirth,
+		Height:         player.Height,
+		Weight:         player.Weight,
+		PreferredFoot:  player.PreferredFoot,
+		Status:         player.Status,
+		Cutout:         player.Cutout,
+		Render:         player.Render,
+	}
+}
+
+// setPlayerProfileField sets a synced field of a player to a value of the provider profile.
+func setPlayerProfileField(player *entity.Player, field string, value any) {
+	switch field {
+	case "first_name":
+		player.FirstName = value.(string)
+	case "last_name":
+		player.LastName = value.(string)
+	case "display_name":
+		player.DisplayName = value.(string)
+	case "known_as":
+		player.KnownAs = value.(string)
+	case "native_name":
+		player.NativeName = value.(string)
+	case "profile_picture":
+		player.ProfilePicture = value.(string)
+	case "position":
+		player.Position = value.(string)
+	case "shirt_number":
+		player.ShirtNumber = value.(int)
+	case "nationality":
+		player.Nationality = value.(string)
+	case "date_of_birth":
+		player.DateOfBirth = value.(string)
+	case "height":
+		player.Height = value.(int)
+	case "weight":
+		player.Weight = value.(int)
+	case "preferred_foot":
+		player.PreferredFoot = value.(string)
+	case "status":
+		player.Status = value.(string)
+	case "cutout":
+		player.Cutout = value.(string)
+	case "render":
+		player.Render = value.(string)
+	}
+}
+
+// editedFields returns the fields of a player edited through the API: the ones changed by the update, on top of the
+// ones given by the update or, when it gives none, the ones edited before.
+func editedFields(current entity.Player, updated entity.Player) []string {
+	edited := current.EditedFields
+	if updated.EditedFields != nil {
+		edited = updated.EditedFields
+	}
+	before, after := playerProfile(current), playerProfile(updated)
+	fields := []string{}
+	for _, field := range syncedPlayerFields {
+		if slices.Contains(edited, field) || !sameFieldValue(before[field], after[field]) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// mergePlayer fills the fields a player misses with the ones of a duplicate, keeping them edited when they were, adds
+// the IDs of the duplicate at the providers, and reports whether the player changed. A duplicate with another ID at a
+// provider of the player is another person.
+func mergePlayer(player *entity.Player, duplicate entity.Player) (bool, error) {
+	changed := false
+	for provider, externalID := range duplicate.ExternalIDs {
+		current, exists := player.ExternalIDs[provider]
+		if exists && current != externalID {
+			return false, exception.InvalidArgumentException{Argument: "player_ids", Reason: fmt.Sprintf(
+				"player %d and player %d have different IDs at %s", player.ID, duplicate.ID, provider)}
+		}
+		if exists {
+			continue
+		}
+		if player.ExternalIDs == nil {
+			player.ExternalIDs = make(map[string]string)
+		}
+		player.ExternalIDs[provider] = externalID
+		changed = true
+	}
+	current, merged := playerProfile(*player), playerProfile(duplicate)
+	for _, field := range syncedPlayerFields {
+		if !isEmptyValue(current[field]) || isEmptyValue(merged[field]) {
+			continue
+		}
+		setPlayerProfileField(player, field, merged[field])
+		if slices.Contains(duplicate.EditedFields, field) && !slices.Contains(player.EditedFields, field) {
+			player.EditedFields = append(player.EditedFields, field)
+		}
+		changed = true
+	}
+	return changed, nil
+}
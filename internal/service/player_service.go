@@ -1,57 +1,250 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"scoreplay/internal/dto"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
 	"scoreplay/internal/mapper"
 	"scoreplay/internal/repository"
+	"slices"
 )
 
 type DefaultPlayerService struct {
-	repo   repository.PlayerRepository
-	mapper mapper.PlayerMapper
+	repository    repository.Repository
+	mapper        mapper.PlayerMapper
+	historyMapper mapper.HistoryMapper
+	deletePolicy  DeletePolicy
 }
 
-func NewDefaultPlayerService(repo repository.PlayerRepository, mapper mapper.PlayerMapper) *DefaultPlayerService {
+func NewDefaultPlayerService(repository repository.Repository, mapper mapper.PlayerMapper, historyMapper mapper.HistoryMapper,
+	deletePolicy DeletePolicy) *DefaultPlayerService {
 	return &DefaultPlayerService{
-		repo:   repo,
-		mapper: mapper,
+		repository:    repository,
+		mapper:        mapper,
+		historyMapper: historyMapper,
+		deletePolicy:  deletePolicy,
 	}
 }
 
-func (s *DefaultPlayerService) GetAllPlayers() ([]dto.PlayerDTO, error) {
-	// Get from repository
-	players, err := s.repo.GetAll()
+func (s *DefaultPlayerService) GetAllPlayers(ctx context.Context, query dto.PlayerQueryDTO) (*dto.PlayerListDTO, error) {
+	// Get the page from repository, which filters, sorts and paginates
+	page := pageFromQuery(query.ListQueryDTO)
+	players, err := s.repository.Player.Find(repository.PlayerQuery{
+		Filter: repository.PlayerFilter{
+			Name:     query.Name,
+			LastName: query.LastName,
+			TeamID:   query.TeamID,
+		},
+		Sort: repository.ParseSort(query.Sort),
+		Page: page,
+	})
 	if err != nil {
 		return nil, err
 	}
 	// Map to DTO list
-	return s.mapper.MapToPlayerDTOList(players), nil
+	return &dto.PlayerListDTO{
+		Players:    append([]dto.PlayerDTO{}, s.mapper.MapToPlayerDTOList(players.Players)...),
+		Total:      players.Total,
+		Offset:     page.Offset,
+		Limit:      page.Limit,
+		NextCursor: players.NextCursor,
+	}, nil
 }
 
-func (s *DefaultPlayerService) GetPlayerByID(id int) (*dto.PlayerDTO, error) {
-	player, err := s.repo.GetByID(id)
+func (s *DefaultPlayerService) GetPlayerByID(ctx context.Context, id int) (*dto.PlayerDTO, error) {
+	player, err := s.repository.Player.GetByID(id)
+	var notFound exception.EntityNotFoundException
+	if errors.As(err, &notFound) {
+		// The ID of a merged duplicate leads to the player it was merged into
+		if intoID, merged := mergedPlayerID(s.repository.Player, id); merged {
+			return nil, exception.EntityMergedException{Entity: "Player", ID: id, MergedInto: intoID}
+		}
+	}
 	if err != nil {
 		return nil, err // Propagate error from repository
 	}
 	return s.mapper.MapToPlayerDTO(*player), nil
 }
 
-func (s *DefaultPlayerService) CreatePlayer(playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error) {
-	playerCreated, err := s.repo.Create(*s.mapper.MapFromPlayerDTO(playerDTO))
+func (s *DefaultPlayerService) CreatePlayer(ctx context.Context, playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error) {
+	var playerCreated *entity.Player
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		var err error
+		playerCreated, err = auditTransaction(ctx, tx).Player().Create(*s.mapper.MapFromPlayerDTO(playerDTO))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	return s.mapper.MapToPlayerDTO(*playerCreated), nil
 }
 
-func (s *DefaultPlayerService) UpdatePlayer(playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error) {
-	updatedPlayer, err := s.repo.Update(*s.mapper.MapFromPlayerDTO(playerDTO))
+func (s *DefaultPlayerService) UpdatePlayer(ctx context.Context, playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error) {
+	player := s.mapper.MapFromPlayerDTO(playerDTO)
+	var updatedPlayer *entity.Player
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		current, err := tx.Player().GetByID(player.ID)
+		if err != nil {
+			return err
+		}
+		// Without an expected version the last write wins
+		if player.Version == 0 {
+			player.Version = current.Version
+		}
+		// The external identifiers are only set by the syncs
+		player.ExternalIDs = current.ExternalIDs
+		player.EditedFields = editedFields(*current, *player)
+		updatedPlayer, err = tx.Player().Update(*player)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	return s.mapper.MapToPlayerDTO(*updatedPlayer), nil
 }
 
-func (s *DefaultPlayerService) DeletePlayer(id int) error {
-	return s.repo.Delete(id)
+func (s *DefaultPlayerService) DeletePlayer(ctx context.Context, id int, version int) error {
+	return s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		player, err := tx.Player().GetByID(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && version != player.Version {
+			return exception.VersionConflictException{Entity: "Player", ID: id, ExpectedVersion: version, ActualVersion: player.Version}
+		}
+		teams, err := tx.Team().GetByPlayerID(id)
+		if err != nil {
+			return err
+		}
+		if len(teams) > 0 && s.deletePolicy == DeletePolicyRestrict {
+			return exception.ReferentialIntegrityException{Entity: "Player", ID: id, Reason: fmt.Sprintf("is on the roster of %d team(s)", len(teams))}
+		}
+		// Remove the player from every roster so none references it once deleted
+		for _, team := range teams {
+			team.Players = removePlayerID(team.Players, id)
+			if _, err := tx.Team().Update(team); err != nil {
+				return err
+			}
+		}
+		return tx.Player().Delete(id)
+	})
+}
+
+// RestorePlayer restores a deleted player, without adding it back to the rosters it was removed from.
+func (s *DefaultPlayerService) RestorePlayer(ctx context.Context, id int) (*dto.PlayerDTO, error) {
+	var playerRestored *entity.Player
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		var err error
+		playerRestored, err = auditTransaction(ctx, tx).Player().Restore(id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.mapper.MapToPlayerDTO(*playerRestored), nil
+}
+
+// MergePlayers merges duplicates into a player. The player keeps its fields, takes the ones it misses and the IDs at
+// the providers from the duplicates, and replaces them on the team rosters. The duplicates are then deleted, their IDs
+// leading to the player.
+func (s *DefaultPlayerService) MergePlayers(ctx context.Context, id int, version int, merge dto.PlayerMergeDTO) (*dto.PlayerDTO, error) {
+	var playerMerged *entity.Player
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		player, err := tx.Player().GetByID(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && version != player.Version {
+			return exception.VersionConflictException{Entity: "Player", ID: id, ExpectedVersion: version, ActualVersion: player.Version}
+		}
+		merged := *player
+		merged.ExternalIDs = maps.Clone(player.ExternalIDs)
+		merged.EditedFields = slices.Clone(player.EditedFields)
+		changed := false
+		for i, duplicateID := range merge.PlayerIDs {
+			if duplicateID == id {
+				return exception.InvalidArgumentException{Argument: "player_ids", Reason: fmt.Sprintf("player %d cannot be merged into itself", id)}
+			}
+			if slices.Contains(merge.PlayerIDs[:i], duplicateID) {
+				return exception.InvalidArgumentException{Argument: "player_ids", Reason: fmt.Sprintf("player %d is listed twice", duplicateID)}
+			}
+			duplicate, err := tx.Player().GetByID(duplicateID)
+			if err != nil {
+				return err
+			}
+			duplicateMerged, err := mergePlayer(&merged, *duplicate)
+			if err != nil {
+				return err
+			}
+			changed = changed || duplicateMerged
+			teams, err := tx.Team().GetByPlayerID(duplicateID)
+			if err != nil {
+				return err
+			}
+			for _, team := range teams {
+				team.Players = replacePlayerID(team.Players, duplicateID, id)
+				if _, err := tx.Team().Update(team); err != nil {
+					return err
+				}
+			}
+			if err := tx.Player().Merge(duplicateID, id); err != nil {
+				return err
+			}
+		}
+		playerMerged = player
+		if !changed {
+			return nil
+		}
+		playerMerged, err = tx.Player().Update(merged)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.mapper.MapToPlayerDTO(*playerMerged), nil
+}
+
+func (s *DefaultPlayerService) GetPlayerHistory(ctx context.Context, id int, offset int, limit int) (*dto.HistoryPageDTO, error) {
+	return getHistory(s.repository.History, s.historyMapper, repository.HistoryEntityPlayer, id, offset, limit, s.playerExisted)
+}
+
+// playerExisted finds a player, live, soft-deleted or merged into another.
+func (s *DefaultPlayerService) playerExisted(id int) error {
+	_, err := s.repository.Player.GetByID(id)
+	var notFound exception.EntityNotFoundException
+	if !errors.As(err, &notFound) {
+		return err
+	}
+	if _, err := s.repository.Player.GetMergedInto(id); !errors.As(err, &notFound) {
+		return err
+	}
+	deleted, err := s.repository.Player.GetDeleted()
+	if err != nil {
+		return err
+	}
+	for _, player := range deleted {
+		if player.ID == id {
+			return nil
+		}
+	}
+	return notFound
+}
+
+// mergedPlayerID follows the merges of a player to the player replacing it, if it was merged.
+func mergedPlayerID(players repository.PlayerRepository, id int) (int, bool) {
+	intoID, err := players.GetMergedInto(id)
+	for err == nil {
+		if _, err := players.GetByID(intoID); err == nil {
+			return intoID, true
+		}
+		intoID, err = players.GetMergedInto(intoID)
+	}
+	return 0, false
 }
@@ -1,29 +1,55 @@
 package service
 
 import (
+	"context"
 	"scoreplay/internal/dto"
 )
 
 type TeamService interface {
-	GetAllTeams() ([]dto.TeamDTO, error)
-	GetTeamByID(id int) (*dto.TeamDTO, error)
-	CreateTeam(teamDTO dto.TeamDTO) (*dto.TeamDTO, error)
-	UpdateTeam(teamDTO dto.TeamDTO) (*dto.TeamDTO, error)
-	DeleteTeam(id int) error
-	GetTeamPlayers(id int) ([]dto.PlayerDTO, error)
-	AddPlayerToTeam(id int, createPlayerDto dto.PlayerDTO) error
-	SyncTeam(id int) error
+	GetAllTeams(ctx context.Context, query dto.TeamQueryDTO) (*dto.TeamListDTO, error)
+	GetTeamByID(ctx context.Context, id int) (*dto.TeamDTO, error)
+	CreateTeam(ctx context.Context, teamDTO dto.TeamDTO) (*dto.TeamDTO, error)
+	UpdateTeam(ctx context.Context, teamDTO dto.TeamDTO) (*dto.TeamDTO, error)
+	DeleteTeam(ctx context.Context, id int, version int) error
+	RestoreTeam(ctx context.Context, id int) (*dto.TeamDTO, error)
+	GetTeamPlayers(ctx context.Context, id int) ([]dto.PlayerDTO, error)
+	AddPlayerToTeam(ctx context.Context, id int, createPlayerDto dto.PlayerDTO) error
+	SyncTeam(ctx context.Context, id int) (*dto.SyncReportDTO, error)
+	PreviewSync(ctx context.Context, id int) (*dto.SyncDiffDTO, error)
+	ApplySync(ctx context.Context, id int, selection dto.SyncSelectionDTO) (*dto.SyncReportDTO, error)
+	RepairRosters(ctx context.Context) (*dto.RosterRepairDTO, error)
+	GetTeamHistory(ctx context.Context, id int, offset int, limit int) (*dto.HistoryPageDTO, error)
 }
 
 type PlayerService interface {
-	GetAllPlayers() ([]dto.PlayerDTO, error)
-	GetPlayerByID(id int) (*dto.PlayerDTO, error)
-	CreatePlayer(playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error)
-	UpdatePlayer(playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error)
-	DeletePlayer(id int) error
+	GetAllPlayers(ctx context.Context, query dto.PlayerQueryDTO) (*dto.PlayerListDTO, error)
+	GetPlayerByID(ctx context.Context, id int) (*dto.PlayerDTO, error)
+	CreatePlayer(ctx context.Context, playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error)
+	UpdatePlayer(ctx context.Context, playerDTO dto.PlayerDTO) (*dto.PlayerDTO, error)
+	DeletePlayer(ctx context.Context, id int, version int) error
+	RestorePlayer(ctx context.Context, id int) (*dto.PlayerDTO, error)
+	MergePlayers(ctx context.Context, id int, version int, merge dto.PlayerMergeDTO) (*dto.PlayerDTO, error)
+	GetPlayerHistory(ctx context.Context, id int, offset int, limit int) (*dto.HistoryPageDTO, error)
+}
+
+type TrashService interface {
+	GetTrash(ctx context.Context) (*dto.TrashDTO, error)
+	PurgeTrash(ctx context.Context) (*dto.PurgeReportDTO, error)
+}
+
+type DuplicateService interface {
+	GetDuplicates(ctx context.Context, query dto.DuplicateQueryDTO) (*dto.DuplicateClustersDTO, error)
+}
+
+type SyncJobService interface {
+	EnqueueTeamSync(ctx context.Context, teamID int) (*dto.SyncJobDTO, error)
+	GetSyncJob(ctx context.Context, id int) (*dto.SyncJobDTO, error)
 }
 
 type Service struct {
-	Team   TeamService
-	Player PlayerService
+	Team      TeamService
+	Player    PlayerService
+	Trash     TrashService
+	Duplicate DuplicateService
+	SyncJob   SyncJobService
 }
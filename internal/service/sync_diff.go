@@ -0,0 +1,399 @@
+package service
+
+import (
+	"fmt"
+	"scoreplay/internal/dto"
+	thirdpartydto "scoreplay/internal/dto/thirdparty"
+	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/names"
+	"scoreplay/internal/repository"
+	"slices"
+	"strings"
+)
+
+// providerLookup is a team and its squad as returned by the provider of the team.
+type providerLookup struct {
+	provider string
+	team     *thirdpartydto.ProviderTeamDto
+	players  []thirdpartydto.ProviderPlayerDto
+	// playersIncomplete counts the players of the squad left without the details looked up by ID
+	playersIncomplete int
+}
+
+// diffSync compares a team and its roster with the team and the squad returned by its provider. The fields of the team
+// edited through the API are left out, and the ones missing upstream do not clear the ones of the team. The players are
+// matched by their ID at the provider, and the ones not linked to the provider yet by their normalized names, ignoring
+// the case, the diacritics and the spacing, so they get linked.
+func diffSync(team *entity.Team, teamPlayers []entity.Player, lookup *providerLookup) *dto.SyncDiffDTO {
+	diff := &dto.SyncDiffDTO{
+		TeamID:                 team.ID,
+		Provider:               lookup.provider,
+		TeamChanges:            []dto.FieldChangeDTO{},
+		PlayersToAdd:           []dto.SyncPlayerAddDTO{},
+		PlayersToUpdate:        []dto.SyncPlayerDiffDTO{},
+		PlayersMissingUpstream: []dto.SyncPlayerMatchDTO{},
+		PlayersIncomplete:      lookup.playersIncomplete,
+	}
+	if externalID := lookup.team.ExternalID; externalID != "" && team.ExternalIDs[lookup.provider] != externalID {
+		diff.ExternalID = &dto.FieldChangeDTO{Field: "external_id", Before: team.ExternalIDs[lookup.provider], After: externalID}
+	}
+	if lookup.team.Logo != team.Logo && !slices.Contains(team.EditedFields, "logo") {
+		diff.Logo = &dto.FieldChangeDTO{Field: "logo", Before: team.Logo, After: lookup.team.Logo}
+	}
+	before, after := teamProfile(*team), providerTeamProfile(lookup.team)
+	for _, field := range syncedTeamFields {
+		if isEmptyValue(after[field]) || sameFieldValue(before[field], after[field]) || slices.Contains(team.EditedFields, field) {
+			continue
+		}
+		diff.TeamChanges = append(diff.TeamChanges, dto.FieldChangeDTO{Field: field, Before: before[field], After: after[field]})
+	}
+
+	linkedPlayers := make(map[string]entity.Player)
+	unlinkedPlayers := make(map[string]entity.Player)
+	for _, player := range teamPlayers {
+		if externalID := player.ExternalIDs[lookup.provider]; externalID != "" {
+			linkedPlayers[externalID] = player
+			continue
+		}
+		for _, name := range playerNameKeys(player) {
+			if _, taken := unlinkedPlayers[name]; !taken {
+				unlinkedPlayers[name] = player
+			}
+		}
+	}
+	matched := make(map[int]struct{})
+	seen := make(map[string]struct{})
+	for _, searchPlayer := range lookup.players {
+		name := names.Normalize(searchPlayer.Name)
+		key := "name:" + name
+		if searchPlayer.ExternalID != "" {
+			key = "id:" + searchPlayer.ExternalID
+		}
+		if _, duplicated := seen[key]; duplicated || name == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		player, exists := linkedPlayers[searchPlayer.ExternalID]
+		if !exists {
+			player, exists = unlinkedPlayers[name]
+			_, alreadyMatched := matched[player.ID]
+			exists = exists && !alreadyMatched
+		}
+		if !exists {
+			diff.PlayersToAdd = append(diff.PlayersToAdd, dto.SyncPlayerAddDTO{
+				ExternalID:     searchPlayer.ExternalID,
+				Name:           searchPlayer.Name,
+				ProfilePicture: searchPlayer.Thumbnail,
+				Position:       searchPlayer.Position,
+				ShirtNumber:    searchPlayer.ShirtNumber,
+				Nationality:    searchPlayer.Nationality,
+				DateOfBirth:    searchPlayer.DateOfBirth,
+				Height:         searchPlayer.Height,
+				Weight:         searchPlayer.Weight,
+				PreferredFoot:  searchPlayer.PreferredFoot,
+				Status:         searchPlayer.Status,
+				Cutout:         searchPlayer.Cutout,
+				Render:         searchPlayer.Render,
+			})
+			continue
+		}
+		matched[player.ID] = struct{}{}
+		if changes := diffPlayer(player, searchPlayer, lookup.provider); len(changes) > 0 {
+			diff.PlayersToUpdate = append(diff.PlayersToUpdate, dto.SyncPlayerDiffDTO{PlayerID: player.ID, Changes: changes})
+		}
+	}
+	for _, player := range teamPlayers {
+		if _, exists := matched[player.ID]; !exists {
+			diff.PlayersMissingUpstream = append(diff.PlayersMissingUpstream, dto.SyncPlayerMatchDTO{
+				PlayerID:   player.ID,
+				ExternalID: player.ExternalIDs[lookup.provider],
+				Name:       fullName(player),
+			})
+		}
+	}
+	return diff
+}
+
+// diffPlayer returns the fields of a player differing from the provider, except the ones edited through the API, and
+// its ID at the provider when it is not linked yet. A profile field missing upstream does not clear the one of the player.
+func diffPlayer(player entity.Player, searchPlayer thirdpartydto.ProviderPlayerDto, provider string) []dto.FieldChangeDTO {
+	changes := []dto.FieldChangeDTO{}
+	if searchPlayer.ExternalID != "" && player.ExternalIDs[provider] != searchPlayer.ExternalID {
+		changes = append(changes, dto.FieldChangeDTO{Field: "external_id", Before: player.ExternalIDs[provider], After: searchPlayer.ExternalID})
+	}
+	before, after := playerProfile(player), playerProfile(providerPlayer(searchPlayer))
+	for _, field := range syncedPlayerFields {
+		if sameFieldValue(before[field], after[field]) || slices.Contains(player.EditedFields, field) {
+			continue
+		}
+		if !slices.Contains(playerNameFields, field) && isEmptyValue(after[field]) {
+			continue
+		}
+		changes = append(changes, dto.FieldChangeDTO{Field: field, Before: before[field], After: after[field]})
+	}
+	return changes
+}
+
+// fullSyncSelection selects what a sync applies on its own: the logo and the team changes, the players to add and to
+// update, and the players that left the provider squad unless the departure policy keeps them. The players only ever
+// added locally stay on the roster.
+func fullSyncSelection(diff *dto.SyncDiffDTO, departurePolicy DeparturePolicy) dto.SyncSelectionDTO {
+	selection := dto.SyncSelectionDTO{Logo: diff.Logo != nil}
+	for _, change := range diff.TeamChanges {
+		selection.Fields = append(selection.Fields, change.Field)
+	}
+	for _, player := range diff.PlayersToAdd {
+		if player.ExternalID != "" {
+			selection.Add = append(selection.Add, player.ExternalID)
+		} else {
+			selection.Add = append(selection.Add, player.Name)
+		}
+	}
+	for _, player := range diff.PlayersToUpdate {
+		selection.Update = append(selection.Update, player.PlayerID)
+	}
+	for _, player := range diff.PlayersMissingUpstream {
+		if player.ExternalID != "" && departurePolicy != DeparturePolicyKeep {
+			selection.Remove = append(selection.Remove, player.PlayerID)
+		}
+	}
+	return selection
+}
+
+// applyFullSync applies to the team what a sync applies on its own, failing when the departures are refused unless forced.
+func (s *DefaultTeamService) applyFullSync(tx repository.Transaction, team *entity.Team, lookup *providerLookup,
+	force bool) (*dto.SyncReportDTO, error) {
+	teamPlayers, err := getTeamPlayers(tx.Team(), tx.Player(), team.ID)
+	if err != nil {
+		return nil, err
+	}
+	diff := diffSync(team, teamPlayers, lookup)
+	selection := fullSyncSelection(diff, s.departurePolicy)
+	if err := s.guardDepartures(lookup, teamPlayers, len(selection.Remove), force); err != nil {
+		return nil, err
+	}
+	return s.applySyncDiff(tx, team, diff, selection)
+}
+
+// guardDepartures refuses to remove players from the roster when the provider returned an empty squad, or when more
+// players left than the maximum share of the roster, as the squad is then more likely incomplete than emptied.
+func (s *DefaultTeamService) guardDepartures(lookup *providerLookup, teamPlayers []entity.Player, departures int, force bool) error {
+	if departures == 0 || force {
+		return nil
+	}
+	if len(lookup.players) == 0 {
+		return exception.InvalidArgumentException{
+			Argument: "remove",
+			Reason:   fmt.Sprintf("the %s squad is empty, force the sync to remove the players anyway", lookup.provider),
+		}
+	}
+	if departures*100 > s.maxDepartures*len(teamPlayers) {
+		return exception.InvalidArgumentException{
+			Argument: "remove",
+			Reason: fmt.Sprintf("%d of the %d players left the %s squad, more than %d%%, force the sync to remove them anyway",
+				departures, len(teamPlayers), lookup.provider, s.maxDepartures),
+		}
+	}
+	return nil
+}
+
+// applySyncDiff applies the selected items of the diff to the team, records the run in the history of the team, and
+// reports the players changed. The external ID of the team is always applied, as it only links the team to the
+// provider. Selecting an item missing from the diff, the logo included, fails with an InvalidArgumentException.
+func (s *DefaultTeamService) applySyncDiff(tx repository.Transaction, team *entity.Team, diff *dto.SyncDiffDTO,
+	selection dto.SyncSelectionDTO) (*dto.SyncReportDTO, error) {
+	teamChanges := make(map[string]dto.FieldChangeDTO)
+	for _, change := range diff.TeamChanges {
+		teamChanges[change.Field] = change
+	}
+	additions := make(map[string]dto.SyncPlayerAddDTO)
+	for _, player := range diff.PlayersToAdd {
+		additions[names.Normalize(player.Name)] = player
+		if player.ExternalID != "" {
+			additions[player.ExternalID] = player
+		}
+	}
+	updates := make(map[int]dto.SyncPlayerDiffDTO)
+	for _, player := range diff.PlayersToUpdate {
+		updates[player.PlayerID] = player
+	}
+	removals := make(map[int]dto.SyncPlayerMatchDTO)
+	for _, player := range diff.PlayersMissingUpstream {
+		removals[player.PlayerID] = player
+	}
+
+	// Sync team data, linking the team to the provider whatever the selection
+	if diff.ExternalID != nil {
+		if team.ExternalIDs == nil {
+			team.ExternalIDs = make(map[string]string)
+		}
+		team.ExternalIDs[diff.Provider] = diff.ExternalID.After.(string)
+	}
+	if selection.Logo {
+		if diff.Logo == nil {
+			return nil, notInSyncDiff("logo", "the logo")
+		}
+		team.Logo = diff.Logo.After.(string)
+	}
+	for _, field := range selection.Fields {
+		change, exists := teamChanges[field]
+		if !exists {
+			return nil, notInSyncDiff("fields", field)
+		}
+		delete(teamChanges, field)
+		setTeamProfileField(team, field, change.After)
+	}
+	// Create the selected players and associate them with the team
+	playersAdded := []string{}
+	for _, item := range selection.Add {
+		addition, exists := additions[item]
+		if !exists {
+			addition, exists = additions[names.Normalize(item)]
+		}
+		if !exists {
+			return nil, notInSyncDiff("add", item)
+		}
+		delete(additions, names.Normalize(addition.Name))
+		delete(additions, addition.ExternalID)
+		name := names.Parse(addition.Name)
+		player := entity.Player{
+			FirstName:      name.Given,
+			LastName:       name.Family,
+			DisplayName:    name.Display,
+			KnownAs:        name.KnownAs,
+			NativeName:     name.Native,
+			ProfilePicture: addition.ProfilePicture,
+			Position:       addition.Position,
+			ShirtNumber:    addition.ShirtNumber,
+			Nationality:    addition.Nationality,
+			DateOfBirth:    addition.DateOfBirth,
+			Height:         addition.Height,
+			Weight:         addition.Weight,
+			PreferredFoot:  addition.PreferredFoot,
+			Status:         addition.Status,
+			Cutout:         addition.Cutout,
+			Render:         addition.Render,
+		}
+		if addition.ExternalID != "" {
+			player.ExternalIDs = map[string]string{diff.Provider: addition.ExternalID}
+		}
+		createdPlayer, err := tx.Player().Create(player)
+		if err != nil {
+			return nil, err
+		}
+		team.Players = append(team.Players, createdPlayer.ID)
+		playersAdded = append(playersAdded, addition.Name)
+	}
+	// Update the selected players with the data of the provider
+	playersUpdated := []string{}
+	for _, playerID := range selection.Update {
+		update, exists := updates[playerID]
+		if !exists {
+			return nil, notInSyncDiff("update", playerID)
+		}
+		delete(updates, playerID)
+		player, err := tx.Player().GetByID(playerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range update.Changes {
+			if change.Field != "external_id" {
+				setPlayerProfileField(player, change.Field, change.After)
+				continue
+			}
+			if player.ExternalIDs == nil {
+				player.ExternalIDs = make(map[string]string)
+			}
+			player.ExternalIDs[diff.Provider] = change.After.(string)
+		}
+		if _, err := tx.Player().Update(*player); err != nil {
+			return nil, err
+		}
+		playersUpdated = append(playersUpdated, fullName(*player))
+	}
+	// Remove the selected players from the roster
+	playersRemoved := []string{}
+	for _, playerID := range selection.Remove {
+		removal, exists := removals[playerID]
+		if !exists {
+			return nil, notInSyncDiff("remove", playerID)
+		}
+		delete(removals, playerID)
+		team.Players = removePlayerID(team.Players, playerID)
+		playersRemoved = append(playersRemoved, removal.Name)
+	}
+
+	// Update the team in the repository
+	teamUpdated, err := tx.Team().Update(*team)
+	if err != nil {
+		return nil, err
+	}
+	*team = *teamUpdated
+	// Archive the removed players on no other roster, the others are kept as free agents
+	playersArchived := 0
+	if s.departurePolicy == DeparturePolicyArchive {
+		for _, playerID := range selection.Remove {
+			teams, err := tx.Team().GetByPlayerID(playerID)
+			if err != nil {
+				return nil, err
+			}
+			if len(teams) > 0 {
+				continue
+			}
+			if err := tx.Player().Delete(playerID); err != nil {
+				return nil, err
+			}
+			playersArchived++
+		}
+	}
+	// Record the run, even when the team was already up to date
+	changes := []entity.FieldChange{{Field: "players_added", Before: nil, After: playersAdded}}
+	if len(playersUpdated) > 0 {
+		changes = append(changes, entity.FieldChange{Field: "players_updated", Before: nil, After: playersUpdated})
+	}
+	if len(playersRemoved) > 0 {
+		changes = append(changes, entity.FieldChange{Field: "players_removed", Before: nil, After: playersRemoved})
+	}
+	_, err = tx.History().Create(entity.HistoryEntry{
+		EntityType: repository.HistoryEntityTeam,
+		EntityID:   team.ID,
+		Action:     repository.HistoryActionSync,
+		Changes:    changes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SyncReportDTO{
+		PlayersCreated:    len(playersAdded),
+		PlayersUpdated:    len(playersUpdated),
+		PlayersRemoved:    len(playersRemoved),
+		PlayersArchived:   playersArchived,
+		PlayersIncomplete: diff.PlayersIncomplete,
+	}, nil
+}
+
+// fullName returns the name of a player as spelled by its provider, or its given and family names.
+func fullName(player entity.Player) string {
+	if player.DisplayName != "" {
+		return player.DisplayName
+	}
+	return strings.TrimSpace(player.FirstName + " " + player.LastName)
+}
+
+// playerNameKeys returns the normalized names a player can be matched by: its full name, its given and family names,
+// and the name it is known as.
+func playerNameKeys(player entity.Player) []string {
+	var keys []string
+	for _, name := range []string{fullName(player), player.FirstName + " " + player.LastName, player.KnownAs} {
+		if key := names.Normalize(name); key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func notInSyncDiff(argument string, item any) error {
+	return exception.InvalidArgumentException{Argument: argument, Reason: fmt.Sprintf("%v is not in the sync diff", item)}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"log"
+	"scoreplay/internal/dto"
+	"scoreplay/internal/exception"
+	"sync"
+	"time"
+)
+
+const (
+	SyncJobQueued    = "queued"
+	SyncJobRunning   = "running"
+	SyncJobSucceeded = "succeeded"
+	SyncJobFailed    = "failed"
+)
+
+// syncJob is a team sync waiting in the queue or run by a worker, ctx carrying the actor who requested it.
+type syncJob struct {
+	dto.SyncJobDTO
+	ctx context.Context
+}
+
+// DefaultSyncJobService runs the team syncs in the background, from an in-process queue served by a pool of workers.
+// The queue holds at most one job per team, and up to the queue size. The jobs are kept in memory for the retention
+// once finished, so their outcome can be looked up.
+type DefaultSyncJobService struct {
+	teamService TeamService
+	retention   time.Duration
+	queueSize   int
+
+	mu      sync.Mutex
+	ready   *sync.Cond
+	jobs    map[int]*syncJob
+	pending []*syncJob
+	// teamJobs are the queued or running jobs, by team
+	teamJobs map[int]*syncJob
+	lastID   int
+}
+
+func NewDefaultSyncJobService(teamService TeamService, retention time.Duration, queueSize int) *DefaultSyncJobService {
+	s := &DefaultSyncJobService{
+		teamService: teamService,
+		retention:   retention,
+		queueSize:   max(queueSize, 1),
+		jobs:        make(map[int]*syncJob),
+		teamJobs:    make(map[int]*syncJob),
+	}
+	s.ready = sync.NewCond(&s.mu)
+	return s
+}
+
+// StartWorkers starts the workers running the queued jobs, at least one.
+func (s *DefaultSyncJobService) StartWorkers(workers int) {
+	for i := 0; i < max(workers, 1); i++ {
+		go s.work()
+	}
+}
+
+// EnqueueTeamSync queues a sync of the team, failing right away when the team does not exist. A team whose sync is
+// already queued or running gets that job back, forced when the new one is and it did not start yet, and a full queue
+// fails with a QueueFullException.
+func (s *DefaultSyncJobService) EnqueueTeamSync(ctx context.Context, teamID int) (*dto.SyncJobDTO, error) {
+	if _, err := s.teamService.GetTeamByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, exists := s.teamJobs[teamID]; exists {
+		if job.State == SyncJobQueued && forcedDepartures(ctx) {
+			job.ctx = WithForcedDepartures(job.ctx)
+		}
+		return s.snapshot(job), nil
+	}
+	if len(s.pending) >= s.queueSize {
+		return nil, exception.QueueFullException{Queue: "sync-jobs", Size: s.queueSize}
+	}
+	s.evictExpired()
+	s.lastID++
+	job := &syncJob{
+		SyncJobDTO: dto.SyncJobDTO{
+			ID:       s.lastID,
+			TeamID:   teamID,
+			State:    SyncJobQueued,
+			QueuedAt: time.Now().UTC(),
+		},
+		// The job outlives the request that queued it
+		ctx: context.WithoutCancel(ctx),
+	}
+	s.jobs[job.ID] = job
+	s.teamJobs[teamID] = job
+	s.pending = append(s.pending, job)
+	s.ready.Signal()
+	return s.snapshot(job), nil
+}
+
+func (s *DefaultSyncJobService) GetSyncJob(ctx context.Context, id int) (*dto.SyncJobDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return nil, exception.EntityNotFoundException{Entity: "SyncJob", ID: id}
+	}
+	return s.snapshot(job), nil
+}
+
+// work runs the queued jobs one after the other, forever.
+func (s *DefaultSyncJobService) work() {
+	for {
+		s.mu.Lock()
+		for len(s.pending) == 0 {
+			s.ready.Wait()
+		}
+		job := s.pending[0]
+		s.pending = s.pending[1:]
+		startedAt := time.Now().UTC()
+		job.State = SyncJobRunning
+		job.StartedAt = &startedAt
+		ctx := job.ctx
+		s.mu.Unlock()
+
+		report, err := s.teamService.SyncTeam(ctx, job.TeamID)
+
+		s.mu.Lock()
+		delete(s.teamJobs, job.TeamID)
+		finishedAt := time.Now().UTC()
+		job.FinishedAt = &finishedAt
+		if err != nil {
+			job.State = SyncJobFailed
+			job.Error = err.Error()
+			log.Printf("Sync job %d of team %d failed: %v", job.ID, job.TeamID, err)
+		} else {
+			job.State = SyncJobSucceeded
+			job.SyncReportDTO = *report
+		}
+		s.mu.Unlock()
+	}
+}
+
+// evictExpired forgets the jobs finished for longer than the retention.
+func (s *DefaultSyncJobService) evictExpired() {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > s.retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// snapshot copies the state of a job, which the workers keep updating.
+func (s *DefaultSyncJobService) snapshot(job *syncJob) *dto.SyncJobDTO {
+	jobDTO := job.SyncJobDTO
+	return &jobDTO
+}
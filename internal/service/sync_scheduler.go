@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"log"
+	"scoreplay/internal/repository"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// SyncScheduler resyncs the teams whose schedule fires, through the sync jobs. The jobs of the teams due at the same
+// minute are queued one every spread interval, so the provider is not called in bursts, and the teams synced less
+// than the minimum interval ago are skipped. The minutes reached while the jobs of a previous one are still being queued
+// are checked with the next one, a team due at several of them being queued once.
+type SyncScheduler struct {
+	repository  repository.Repository
+	syncJobs    SyncJobService
+	schedule    *CronSchedule
+	spread      time.Duration
+	minInterval time.Duration
+	schedules   map[string]*CronSchedule
+	// enqueuing is set while the jobs of a minute are being queued, the minutes reached meanwhile being deferred
+	enqueuing atomic.Bool
+	deferred  []time.Time
+}
+
+// NewSyncScheduler creates a scheduler of the global schedule, nil for the teams to only be synced on their own schedule.
+func NewSyncScheduler(repository repository.Repository, syncJobs SyncJobService, schedule *CronSchedule,
+	spread time.Duration, minInterval time.Duration) *SyncScheduler {
+	return &SyncScheduler{
+		repository:  repository,
+		syncJobs:    syncJobs,
+		schedule:    schedule,
+		spread:      spread,
+		minInterval: minInterval,
+		schedules:   make(map[string]*CronSchedule),
+	}
+}
+
+// Run checks the schedules at the start of every minute until the context is done.
+func (s *SyncScheduler) Run(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.syncDueTeams(ctx, next)
+	}
+}
+
+// syncDueTeams queues the sync jobs of the teams due at the minute, or at the deferred ones. The minute is deferred while
+// the previous jobs are still being queued.
+func (s *SyncScheduler) syncDueTeams(ctx context.Context, minute time.Time) {
+	if s.enqueuing.Load() {
+		log.Printf("Deferring the syncs due at %s, the previous ones are still being scheduled", minute.Format("15:04"))
+		s.deferred = append(s.deferred, minute)
+		return
+	}
+	teams, err := s.repository.Team.GetAll()
+	if err != nil {
+		log.Printf("Error listing the teams to sync: %v", err)
+		return
+	}
+	minutes := append(s.deferred, minute)
+	s.deferred = nil
+
+	var dueTeamIDs []int
+	for _, team := range teams {
+		schedule := s.teamSchedule(team.ID, team.SyncSchedule)
+		if schedule == nil || !slices.ContainsFunc(minutes, schedule.Matches) {
+			continue
+		}
+		// A failed sync is retried on the next due minute rather than waiting the minimum interval
+		if team.LastSyncStatus == SyncJobSucceeded && team.LastSyncedAt != nil && minute.Sub(*team.LastSyncedAt) < s.minInterval {
+			continue
+		}
+		dueTeamIDs = append(dueTeamIDs, team.ID)
+	}
+	if len(dueTeamIDs) == 0 {
+		return
+	}
+
+	log.Printf("Scheduling the sync of %d teams", len(dueTeamIDs))
+	s.enqueuing.Store(true)
+	go func() {
+		defer s.enqueuing.Store(false)
+		for i, teamID := range dueTeamIDs {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(s.spread):
+				}
+			}
+			if _, err := s.syncJobs.EnqueueTeamSync(ctx, teamID); err != nil {
+				log.Printf("Error scheduling the sync of team %d: %v", teamID, err)
+			}
+		}
+	}()
+}
+
+// teamSchedule returns the schedule of a team, the global one unless it has its own, nil when it is not synced.
+func (s *SyncScheduler) teamSchedule(teamID int, spec string) *CronSchedule {
+	switch spec {
+	case "":
+		return s.schedule
+	case SyncScheduleOff:
+		return nil
+	}
+	if schedule, exists := s.schedules[spec]; exists {
+		return schedule
+	}
+	schedule, err := ParseCronSchedule(spec)
+	if err != nil {
+		log.Printf("Error parsing the sync schedule of team %d: %v", teamID, err)
+	}
+	s.schedules[spec] = schedule
+	return schedule
+}
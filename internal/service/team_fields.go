@@ -0,0 +1,136 @@
+package service
+
+import (
+	"maps"
+	"reflect"
+	thirdpartydto "scoreplay/internal/dto/thirdparty"
+	"scoreplay/internal/entity"
+	"slices"
+)
+
+// syncedTeamFields are the fields of the team profile the syncs update, unless they were edited through the API. The
+// logo is synced too, on its own.
+var syncedTeamFields = []string{"short_name", "alternate_names", "formed_year", "sport", "league", "country", "stadium",
+	"stadium_capacity", "colors", "badge", "banner", "jersey", "fanart", "website", "social_links", "description"}
+
+// teamProfile returns the values of the synced fields of a team, by field.
+func teamProfile(team entity.Team) map[string]any {
+	return map[string]any{
+		"logo":             team.Logo,
+		"short_name":       team.ShortName,
+		"alternate_names":  team.AlternateNames,
+		"formed_year":      team.FormedYear,
+		"sport":            team.Sport,
+		"league":           team.League,
+		"country":          team.Country,
+		"stadium":          team.Stadium,
+		"stadium_capacity": team.StadiumCapacity,
+		"colors":           team.Colors,
+		"badge":            team.Badge,
+		"banner":           team.Banner,
+		"jersey":           team.Jersey,
+		"fanart":           team.Fanart,
+		"website":          team.Website,
+		"social_links":     team.SocialLinks,
+		"description":      team.Description,
+	}
+}
+
+// providerTeamProfile returns the values of the synced fields of a team returned by a provider, by field.
+func providerTeamProfile(team *thirdpartydto.ProviderTeamDto) map[string]any {
+	return teamProfile(entity.Team{
+		Logo:            team.Logo,
+		ShortName:       team.ShortName,
+		AlternateNames:  team.AlternateNames,
+		FormedYear:      team.FormedYear,
+		Sport:           team.Sport,
+		League:          team.League,
+		Country:         team.Country,
+		Stadium:         team.Stadium,
+		StadiumCapacity: team.StadiumCapacity,
+		Colors:          team.Colors,
+		Badge:           team.Badge,
+		Banner:          team.Banner,
+		Jersey:          team.Jersey,
+		Fanart:          team.Fanart,
+		Website:         team.Website,
+		SocialLinks:     team.SocialLinks,
+		Description:     team.Description,
+	})
+}
+
+// setTeamProfileField sets a synced field of a team to a value of the provider profile.
+func setTeamProfileField(team *entity.Team, field string, value any) {
+	switch field {
+	case "short_name":
+		team.ShortName = value.(string)
+	case "alternate_names":
+		team.AlternateNames = slices.Clone(value.([]string))
+	case "formed_year":
+		team.FormedYear = value.(int)
+	case "sport":
+		team.Sport = value.(string)
+	case "league":
+		team.League = value.(string)
+	case "country":
+		team.Country = value.(string)
+	case "stadium":
+		team.Stadium = value.(string)
+	case "stadium_capacity":
+		team.StadiumCapacity = value.(int)
+	case "colors":
+		team.Colors = slices.Clone(value.([]string))
+	case "badge":
+		team.Badge = value.(string)
+	case "banner":
+		team.Banner = value.(string)
+	case "jersey":
+		team.Jersey = value.(string)
+	case "fanart":
+		team.Fanart = slices.Clone(value.([]string))
+	case "website":
+		team.Website = value.(string)
+	case "social_links":
+		team.SocialLinks = maps.Clone(value.(map[string]string))
+	case "description":
+		team.Description = value.(string)
+	}
+}
+
+// editedTeamFields returns the synced fields of a team edited through the API: the ones changed by the update, on top
+// of the ones given by the update or, when it gives none, the ones edited before.
+func editedTeamFields(current entity.Team, updated entity.Team) []string {
+	edited := current.EditedFields
+	if updated.EditedFields != nil {
+		edited = updated.EditedFields
+	}
+	before, after := teamProfile(current), teamProfile(updated)
+	fields := []string{}
+	for _, field := range append([]string{"logo"}, syncedTeamFields...) {
+		if slices.Contains(edited, field) || !sameFieldValue(before[field], after[field]) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// sameFieldValue compares two field values, considering the empty lists and maps equal to missing ones.
+func sameFieldValue(a any, b any) bool {
+	if isEmptyValue(a) && isEmptyValue(b) {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isEmptyValue tells if a field value is the zero value, or an empty list or map.
+func isEmptyValue(value any) bool {
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map:
+		return reflected.Len() == 0
+	default:
+		return reflected.IsZero()
+	}
+}
@@ -1,46 +1,80 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
 	"scoreplay/internal/dto"
+	thirdpartydto "scoreplay/internal/dto/thirdparty"
 	"scoreplay/internal/entity"
+	"scoreplay/internal/exception"
 	"scoreplay/internal/mapper"
 	"scoreplay/internal/repository"
 	"scoreplay/internal/service/thirdparty"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 type DefaultTeamService struct {
-	teamRepository     repository.TeamRepository
-	playerRepository   repository.PlayerRepository
-	teamMapper         mapper.TeamMapper
-	playerMapper       mapper.PlayerMapper
-	theSportsDBService thirdparty.TheSportsDBService
+	repository      repository.Repository
+	teamMapper      mapper.TeamMapper
+	playerMapper    mapper.PlayerMapper
+	historyMapper   mapper.HistoryMapper
+	providers       *thirdparty.ProviderRegistry
+	deletePolicy    DeletePolicy
+	departurePolicy DeparturePolicy
+	// maxDepartures is the share of a roster, in percent, a sync removes without being forced
+	maxDepartures int
+	teamLocks     sync.Map
 }
 
-func NewDefaultTeamService(teamRepository repository.TeamRepository, playerRepository repository.PlayerRepository,
-	teamMapper mapper.TeamMapper, playerMapper mapper.PlayerMapper, theSportsDBService thirdparty.TheSportsDBService) *DefaultTeamService {
+func NewDefaultTeamService(repository repository.Repository, teamMapper mapper.TeamMapper, playerMapper mapper.PlayerMapper,
+	historyMapper mapper.HistoryMapper, providers *thirdparty.ProviderRegistry, deletePolicy DeletePolicy,
+	departurePolicy DeparturePolicy, maxDepartures int) *DefaultTeamService {
 	return &DefaultTeamService{
-		teamRepository:     teamRepository,
-		playerRepository:   playerRepository,
-		teamMapper:         teamMapper,
-		playerMapper:       playerMapper,
-		theSportsDBService: theSportsDBService,
+		repository:      repository,
+		teamMapper:      teamMapper,
+		playerMapper:    playerMapper,
+		historyMapper:   historyMapper,
+		providers:       providers,
+		deletePolicy:    deletePolicy,
+		departurePolicy: departurePolicy,
+		maxDepartures:   maxDepartures,
 	}
 }
 
-func (s *DefaultTeamService) GetAllTeams() ([]dto.TeamDTO, error) {
-	// Get from repository
-	teams, err := s.teamRepository.GetAll()
+func (s *DefaultTeamService) GetAllTeams(ctx context.Context, query dto.TeamQueryDTO) (*dto.TeamListDTO, error) {
+	// Get the page from repository, which filters, sorts and paginates
+	page := pageFromQuery(query.ListQueryDTO)
+	teams, err := s.repository.Team.Find(repository.TeamQuery{
+		Filter: repository.TeamFilter{
+			Name:     query.Name,
+			HasLogo:  query.HasLogo,
+			PlayerID: query.PlayerID,
+		},
+		Sort: repository.ParseSort(query.Sort),
+		Page: page,
+	})
 	if err != nil {
 		return nil, err // Propagate error from repository
 	}
 	// Map to DTO list
-	return s.teamMapper.MapToTeamDTOList(teams), nil
+	return &dto.TeamListDTO{
+		Teams:      append([]dto.TeamDTO{}, s.teamMapper.MapToTeamDTOList(teams.Teams)...),
+		Total:      teams.Total,
+		Offset:     page.Offset,
+		Limit:      page.Limit,
+		NextCursor: teams.NextCursor,
+	}, nil
 }
 
-func (s *DefaultTeamService) GetTeamByID(id int) (*dto.TeamDTO, error) {
+func (s *DefaultTeamService) GetTeamByID(ctx context.Context, id int) (*dto.TeamDTO, error) {
 	// Get from repository
-	team, err := s.teamRepository.GetByID(id)
+	team, err := s.repository.Team.GetByID(id)
 	if err != nil {
 		return nil, err // Propagate error from repository
 	}
@@ -48,15 +82,44 @@ func (s *DefaultTeamService) GetTeamByID(id int) (*dto.TeamDTO, error) {
 	return s.teamMapper.MapToTeamDTO(*team), nil
 }
 
-func (s *DefaultTeamService) CreateTeam(teamDTO dto.TeamDTO) (*dto.TeamDTO, error) {
-	// Map DTO to entity
-	// Create in the repository
-	teamCreated, err := s.teamRepository.Create(*s.teamMapper.MapFromTeamDTO(teamDTO))
+func (s *DefaultTeamService) CreateTeam(ctx context.Context, teamDTO dto.TeamDTO) (*dto.TeamDTO, error) {
+	if err := validateSyncSchedule(teamDTO.SyncSchedule); err != nil {
+		return nil, err
+	}
+	if _, err := s.providers.Get(teamDTO.Provider); err != nil {
+		return nil, err
+	}
+	if err := s.validateExternalIDs(teamDTO.ExternalIDs); err != nil {
+		return nil, err
+	}
+	// Map DTO to entity, the synced fields it gives being kept by the syncs
+	team := s.teamMapper.MapFromTeamDTO(teamDTO)
+	team.EditedFields = editedTeamFields(entity.Team{}, *team)
+	// Search team on its provider before anything is created. A team the provider does not know, or that cannot be
+	// looked up, is still created, and synced by the next sync that finds it
+	lookup, err := s.searchTeam(ctx, team, true)
 	if err != nil {
-		return nil, err // Propagate error from repository
+		log.Printf("Warning: creating team %q without syncing it: %v", team.Name, err)
 	}
-	// Sync Team
-	if s.SyncTeam(teamCreated.ID) != nil {
+	// Create and sync the team all-or-nothing
+	var teamCreated *entity.Team
+	err = s.repository.InTransaction(func(tx repository.Transaction) error {
+		var err error
+		if teamCreated, err = auditTransaction(ctx, tx).Team().Create(*team); err != nil {
+			return err
+		}
+		if lookup == nil {
+			return nil
+		}
+		syncTx := auditTransaction(withSource(ctx, repository.HistorySourceSync), tx)
+		if _, err := s.applyFullSync(syncTx, teamCreated, lookup, false); err != nil {
+			return err
+		}
+		syncedAt := time.Now().UTC()
+		teamCreated.LastSyncedAt, teamCreated.LastSyncStatus = &syncedAt, SyncJobSucceeded
+		return tx.Team().RecordSync(teamCreated.ID, syncedAt, SyncJobSucceeded)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -64,9 +127,41 @@ func (s *DefaultTeamService) CreateTeam(teamDTO dto.TeamDTO) (*dto.TeamDTO, erro
 	return s.teamMapper.MapToTeamDTO(*teamCreated), nil
 }
 
-func (s *DefaultTeamService) UpdateTeam(teamDTO dto.TeamDTO) (*dto.TeamDTO, error) {
-	// Map from DTO to entity
-	teamUpdated, err := s.teamRepository.Update(*s.teamMapper.MapFromTeamDTO(teamDTO))
+func (s *DefaultTeamService) UpdateTeam(ctx context.Context, teamDTO dto.TeamDTO) (*dto.TeamDTO, error) {
+	// The team can only be synced with a registered provider, on a valid schedule
+	if _, err := s.providers.Get(teamDTO.Provider); err != nil {
+		return nil, err
+	}
+	if err := validateSyncSchedule(teamDTO.SyncSchedule); err != nil {
+		return nil, err
+	}
+	if err := s.validateExternalIDs(teamDTO.ExternalIDs); err != nil {
+		return nil, err
+	}
+	unlock := s.lockTeam(teamDTO.ID)
+	defer unlock()
+
+	var teamUpdated *entity.Team
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		current, err := tx.Team().GetByID(teamDTO.ID)
+		if err != nil {
+			return err
+		}
+		// Map from DTO to entity, the roster is managed by its own endpoints
+		team := s.teamMapper.MapFromTeamDTO(teamDTO)
+		team.Players = current.Players
+		if team.ExternalIDs == nil {
+			team.ExternalIDs = current.ExternalIDs
+		}
+		team.EditedFields = editedTeamFields(*current, *team)
+		// Without an expected version the last write wins
+		if team.Version == 0 {
+			team.Version = current.Version
+		}
+		teamUpdated, err = tx.Team().Update(*team)
+		return err
+	})
 	if err != nil {
 		return nil, err // Propagate error from repository
 	}
@@ -74,117 +169,454 @@ func (s *DefaultTeamService) UpdateTeam(teamDTO dto.TeamDTO) (*dto.TeamDTO, erro
 	return s.teamMapper.MapToTeamDTO(*teamUpdated), nil
 }
 
-func (s *DefaultTeamService) DeleteTeam(id int) error {
-	return s.teamRepository.Delete(id)
+func (s *DefaultTeamService) DeleteTeam(ctx context.Context, id int, version int) error {
+	unlock := s.lockTeam(id)
+	defer unlock()
+
+	return s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		team, err := tx.Team().GetByID(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && version != team.Version {
+			return exception.VersionConflictException{Entity: "Team", ID: id, ExpectedVersion: version, ActualVersion: team.Version}
+		}
+		if len(team.Players) > 0 && s.deletePolicy == DeletePolicyRestrict {
+			return exception.ReferentialIntegrityException{Entity: "Team", ID: id, Reason: fmt.Sprintf("has %d player(s) on its roster", len(team.Players))}
+		}
+		if err := tx.Team().Delete(id); err != nil {
+			return err
+		}
+		if s.deletePolicy != DeletePolicyCascade {
+			return nil
+		}
+		// Delete the players that are not on the roster of another team
+		for _, playerID := range team.Players {
+			teams, err := tx.Team().GetByPlayerID(playerID)
+			if err != nil {
+				return err
+			}
+			if len(teams) > 0 {
+				continue
+			}
+			if err := tx.Player().Delete(playerID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (s *DefaultTeamService) GetTeamPlayers(id int) ([]dto.PlayerDTO, error) {
-	// Fetch players for the team
-	players, err := s.teamRepository.GetPlayersByID(id)
+// RestoreTeam restores a deleted team with its roster as it was, restoring its deleted players too.
+// The players purged since are left out of the roster.
+func (s *DefaultTeamService) RestoreTeam(ctx context.Context, id int) (*dto.TeamDTO, error) {
+	unlock := s.lockTeam(id)
+	defer unlock()
+
+	var teamRestored *entity.Team
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		team, err := tx.Team().Restore(id)
+		if err != nil {
+			return err
+		}
+		roster := make([]int, 0, len(team.Players))
+		for _, playerID := range team.Players {
+			if _, err := tx.Player().GetByID(playerID); err == nil {
+				roster = append(roster, playerID)
+				continue
+			}
+			if _, err := tx.Player().Restore(playerID); err == nil {
+				roster = append(roster, playerID)
+				continue
+			}
+			// A player merged while the team was deleted is replaced by the one it was merged into
+			intoID, merged := mergedPlayerID(tx.Player(), playerID)
+			if merged && !slices.Contains(roster, intoID) && !slices.Contains(team.Players, intoID) {
+				roster = append(roster, intoID)
+			}
+		}
+		teamRestored = team
+		if slices.Equal(roster, team.Players) {
+			return nil
+		}
+		team.Players = roster
+		teamRestored, err = tx.Team().Update(*team)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return s.teamMapper.MapToTeamDTO(*teamRestored), nil
+}
 
-	// Fetch complete player details
-	var fullPlayers []dto.PlayerDTO
-	for _, playerID := range players {
-		player, err := s.playerRepository.GetByID(playerID.ID)
+func (s *DefaultTeamService) GetTeamPlayers(ctx context.Context, id int) ([]dto.PlayerDTO, error) {
+	players, err := getTeamPlayers(s.repository.Team, s.repository.Player, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.playerMapper.MapToPlayerDTOList(players), nil
+}
+
+func (s *DefaultTeamService) AddPlayerToTeam(ctx context.Context, id int, playerDto dto.PlayerDTO) error {
+	unlock := s.lockTeam(id)
+	defer unlock()
+
+	return s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		// Check if the team exists
+		team, err := tx.Team().GetByID(id)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		// Create the player in the repository
+		createdPlayer, err := tx.Player().Create(*s.playerMapper.MapFromPlayerDTO(playerDto))
+		if err != nil {
+			return err
+		}
+
+		// Associate player with the team
+		team.Players = append(team.Players, createdPlayer.ID)
+
+		// Update the team in the repository
+		_, err = tx.Team().Update(*team)
+		return err
+	})
+}
+
+func (s *DefaultTeamService) SyncTeam(ctx context.Context, id int) (*dto.SyncReportDTO, error) {
+	unlock := s.lockTeam(id)
+	defer unlock()
+
+	team, err := s.repository.Team.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.syncTeam(ctx, team)
+	if err != nil {
+		// Record the failure, the sync is reported failed whether or not it could be recorded
+		if recordErr := s.repository.Team.RecordSync(id, time.Now().UTC(), SyncJobFailed); recordErr != nil {
+			log.Printf("Error recording the failed sync of team %d: %v", id, recordErr)
 		}
-		fullPlayers = append(fullPlayers, *s.playerMapper.MapToPlayerDTO(*player))
+		return nil, err
 	}
+	return report, nil
+}
 
-	return fullPlayers, nil
+// syncTeam looks up the team on its provider and applies the changes all-or-nothing, recording the successful sync.
+func (s *DefaultTeamService) syncTeam(ctx context.Context, team *entity.Team) (*dto.SyncReportDTO, error) {
+	// Search team and its players on its provider
+	lookup, err := s.searchTeam(ctx, team, true)
+	if err != nil {
+		return nil, err
+	}
+	// Apply the changes all-or-nothing
+	var report *dto.SyncReportDTO
+	err = s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(withSource(ctx, repository.HistorySourceSync), tx)
+		team, err := tx.Team().GetByID(team.ID)
+		if err != nil {
+			return err
+		}
+		if report, err = s.applyFullSync(tx, team, lookup, forcedDepartures(ctx)); err != nil {
+			return err
+		}
+		return tx.Team().RecordSync(team.ID, time.Now().UTC(), SyncJobSucceeded)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
 }
 
-func (s *DefaultTeamService) AddPlayerToTeam(id int, playerDto dto.PlayerDTO) error {
-	// Check if the team exists
-	team, err := s.teamRepository.GetByID(id)
+// PreviewSync looks up the team on its provider like a sync, and returns what the sync could change without changing it.
+// The preview being read-only, it does not spend the request quota looking up the details of the players.
+func (s *DefaultTeamService) PreviewSync(ctx context.Context, id int) (*dto.SyncDiffDTO, error) {
+	team, err := s.repository.Team.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	lookup, err := s.searchTeam(ctx, team, false)
+	if err != nil {
+		return nil, err
+	}
+	teamPlayers, err := getTeamPlayers(s.repository.Team, s.repository.Player, id)
+	if err != nil {
+		return nil, err
+	}
+	return diffSync(team, teamPlayers, lookup), nil
+}
 
-	// Create the player in the repository
-	createdPlayer, err := s.playerRepository.Create(*s.playerMapper.MapFromPlayerDTO(playerDto))
+// ApplySync looks up the team on its provider again and applies the selected items of the diff all-or-nothing, the
+// selection failing when an item is no longer in the diff, or when the departures it removes are refused unless forced.
+func (s *DefaultTeamService) ApplySync(ctx context.Context, id int, selection dto.SyncSelectionDTO) (*dto.SyncReportDTO, error) {
+	unlock := s.lockTeam(id)
+	defer unlock()
+
+	team, err := s.repository.Team.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := s.searchTeam(ctx, team, true)
 	if err != nil {
+		return nil, err
+	}
+	var report *dto.SyncReportDTO
+	err = s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(withSource(ctx, repository.HistorySourceSync), tx)
+		team, err := tx.Team().GetByID(id)
+		if err != nil {
+			return err
+		}
+		teamPlayers, err := getTeamPlayers(tx.Team(), tx.Player(), id)
+		if err != nil {
+			return err
+		}
+		if err := s.guardDepartures(lookup, teamPlayers, len(selection.Remove), selection.Force); err != nil {
+			return err
+		}
+		report, err = s.applySyncDiff(tx, team, diffSync(team, teamPlayers, lookup), selection)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return report, nil
+}
 
-	// Associate player with the team
-	team.Players = append(team.Players, createdPlayer.ID)
+// RepairRosters removes the references to missing players, and duplicated references, from every team roster.
+func (s *DefaultTeamService) RepairRosters(ctx context.Context) (*dto.RosterRepairDTO, error) {
+	report := &dto.RosterRepairDTO{}
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		players, err := tx.Player().GetAll()
+		if err != nil {
+			return err
+		}
+		playerIDs := make(map[int]struct{})
+		for _, player := range players {
+			playerIDs[player.ID] = struct{}{}
+		}
 
-	// Update the team in the repository
-	if _, err := s.teamRepository.Update(*team); err != nil {
-		return err
+		teams, err := tx.Team().GetAll()
+		if err != nil {
+			return err
+		}
+		for _, team := range teams {
+			roster := make([]int, 0, len(team.Players))
+			seen := make(map[int]struct{})
+			for _, playerID := range team.Players {
+				_, exists := playerIDs[playerID]
+				_, duplicated := seen[playerID]
+				if !exists || duplicated {
+					continue
+				}
+				seen[playerID] = struct{}{}
+				roster = append(roster, playerID)
+			}
+			if len(roster) == len(team.Players) {
+				continue
+			}
+			report.TeamsRepaired++
+			report.ReferencesRemoved += len(team.Players) - len(roster)
+			team.Players = roster
+			if _, err := tx.Team().Update(team); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return report, nil
+}
 
-	return nil
+func (s *DefaultTeamService) GetTeamHistory(ctx context.Context, id int, offset int, limit int) (*dto.HistoryPageDTO, error) {
+	return getHistory(s.repository.History, s.historyMapper, repository.HistoryEntityTeam, id, offset, limit, s.teamExisted)
 }
 
-func (s *DefaultTeamService) SyncTeam(id int) error {
-	team, err := s.teamRepository.GetByID(id)
-	if err != nil {
+// teamExisted finds a team, live or soft-deleted.
+func (s *DefaultTeamService) teamExisted(id int) error {
+	_, err := s.repository.Team.GetByID(id)
+	var notFound exception.EntityNotFoundException
+	if !errors.As(err, &notFound) {
 		return err
 	}
-	teamPlayers, err := s.GetTeamPlayers(id)
+	deleted, err := s.repository.Team.GetDeleted()
 	if err != nil {
 		return err
 	}
-	// Search team on third party API
-	searchTeam, err := s.theSportsDBService.SearchTeam(team.Name)
+	for _, team := range deleted {
+		if team.ID == id {
+			return nil
+		}
+	}
+	return notFound
+}
+
+// validateExternalIDs checks the identifiers pinning a team at the providers are of registered providers.
+func (s *DefaultTeamService) validateExternalIDs(externalIDs map[string]string) error {
+	for provider, externalID := range externalIDs {
+		if _, err := s.providers.Get(provider); err != nil || provider == "" {
+			return exception.InvalidArgumentException{Argument: "external_ids", Reason: fmt.Sprintf("unknown provider %q", provider)}
+		}
+		if strings.TrimSpace(externalID) == "" {
+			return exception.InvalidArgumentException{Argument: "external_ids", Reason: fmt.Sprintf("empty identifier at %q", provider)}
+		}
+	}
+	return nil
+}
+
+// searchTeam looks up a team and its players on the provider authoritative for the team, completing the details of
+// the players when complete is set.
+func (s *DefaultTeamService) searchTeam(ctx context.Context, team *entity.Team, complete bool) (*providerLookup, error) {
+	provider, err := s.providers.Get(team.Provider)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Search team players on third party API
-	searchPlayers, err := s.theSportsDBService.SearchPlayers(team.Name)
+	var searchTeam *thirdpartydto.ProviderTeamDto
+	var searchPlayers []thirdpartydto.ProviderPlayerDto
+	// Look up the team and its players by ID once linked to the provider, by name otherwise
+	if externalID := team.ExternalIDs[provider.Name()]; externalID != "" {
+		if searchTeam, err = provider.LookupTeamByID(ctx, externalID); err != nil {
+			return nil, err
+		}
+		if searchPlayers, err = provider.LookupSquadByTeamID(ctx, externalID); err != nil {
+			return nil, err
+		}
+	} else {
+		if searchTeam, err = provider.LookupTeam(ctx, team.Name); err != nil {
+			return nil, err
+		}
+		if searchPlayers, err = provider.LookupSquad(ctx, team.Name); err != nil {
+			return nil, err
+		}
+	}
+	lookup := &providerLookup{provider: provider.Name(), team: searchTeam, players: searchPlayers}
+	if complete {
+		lookup.playersIncomplete = completeSquad(ctx, provider, searchPlayers)
+	} else {
+		lookup.playersIncomplete = len(incompletePlayers(searchPlayers))
+	}
+	return lookup, nil
+}
+
+// completeSquad looks up by ID the players of a squad missing the details the squad listings tend to leave out, their
+// height, weight and preferred foot, adds the details found to the squad, and returns the count of the players left
+// without them. The lookups are best-effort: they only use the quota left without waiting for it, and the first one
+// failing stops them, the squad keeping what it has.
+func completeSquad(ctx context.Context, provider thirdparty.SportsDataProvider, squad []thirdpartydto.ProviderPlayerDto) int {
+	ctx = thirdparty.WithOptionalCalls(ctx)
+	incomplete := incompletePlayers(squad)
+	for attempted, i := range incomplete {
+		details, err := provider.LookupPlayer(ctx, squad[i].ExternalID)
+		var thirdPartyException *exception.ThirdPartyException
+		if errors.As(err, &thirdPartyException) && thirdPartyException.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			log.Printf("Warning: stopped completing the squad of %s at player %s, %d players left without their details: %v",
+				provider.Name(), squad[i].ExternalID, len(incomplete)-attempted, err)
+			return len(incomplete) - attempted
+		}
+		squad[i] = mergePlayerDetails(squad[i], *details)
+	}
+	return 0
+}
+
+// incompletePlayers returns the indexes of the players of a squad missing the details looked up by ID.
+func incompletePlayers(squad []thirdpartydto.ProviderPlayerDto) []int {
+	var incomplete []int
+	for i, player := range squad {
+		if player.ExternalID != "" && player.Height == 0 && player.Weight == 0 && player.PreferredFoot == "" {
+			incomplete = append(incomplete, i)
+		}
+	}
+	return incomplete
+}
+
+// mergePlayerDetails overrides the fields of a squad player with the ones its lookup returned, keeping the ones the
+// lookup left out.
+func mergePlayerDetails(player thirdpartydto.ProviderPlayerDto, details thirdpartydto.ProviderPlayerDto) thirdpartydto.ProviderPlayerDto {
+	mergeString := func(current *string, found string) {
+		if found != "" {
+			*current = found
+		}
+	}
+	mergeInt := func(current *int, found int) {
+		if found != 0 {
+			*current = found
+		}
+	}
+	mergeString(&player.ExternalID, details.ExternalID)
+	mergeString(&player.Name, details.Name)
+	mergeString(&player.Thumbnail, details.Thumbnail)
+	mergeString(&player.Position, details.Position)
+	mergeInt(&player.ShirtNumber, details.ShirtNumber)
+	mergeString(&player.Nationality, details.Nationality)
+	mergeString(&player.DateOfBirth, details.DateOfBirth)
+	mergeInt(&player.Height, details.Height)
+	mergeInt(&player.Weight, details.Weight)
+	mergeString(&player.PreferredFoot, details.PreferredFoot)
+	mergeString(&player.Status, details.Status)
+	mergeString(&player.Cutout, details.Cutout)
+	mergeString(&player.Render, details.Render)
+	return player
+}
+
+// getTeamPlayers fetches the complete details of the players in the roster of a team.
+func getTeamPlayers(teamRepository repository.TeamRepository, playerRepository repository.PlayerRepository, id int) ([]entity.Player, error) {
+	// Fetch players for the team
+	players, err := teamRepository.GetPlayersByID(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Create a set of existing player names
-	teamPlayersNames := make(map[string]struct{})
-	for _, player := range teamPlayers {
-		if player.LastName != "" {
-			teamPlayersNames[player.FirstName+" "+player.LastName] = struct{}{}
-		} else {
-			teamPlayersNames[player.FirstName] = struct{}{}
-		}
-	}
-	// Sync team data
-	team.Logo = searchTeam.TeamLogo
-	// Loop through team players returned by the search
-	for _, player := range searchPlayers {
-		playerName := player.PlayerName
-		// Create the player in the repository if it does not exist
-		if _, exists := teamPlayersNames[playerName]; !exists {
-			// Split full name into first name and last name
-			firstName, lastName := splitName(playerName)
-			createdPlayer, err := s.playerRepository.Create(entity.Player{
-				FirstName:      firstName,
-				LastName:       lastName,
-				ProfilePicture: player.Thumbnail,
-			})
-			if err != nil {
-				return err
-			}
-			// Associate player with the team
-			team.Players = append(team.Players, createdPlayer.ID)
-			// Update the team in the repository
-			if _, err := s.teamRepository.Update(*team); err != nil {
-				return err
-			}
+
+	// Fetch complete player details
+	var fullPlayers []entity.Player
+	for _, playerID := range players {
+		player, err := playerRepository.GetByID(playerID.ID)
+		if err != nil {
+			return nil, err
 		}
+		fullPlayers = append(fullPlayers, *player)
 	}
 
-	return nil
+	return fullPlayers, nil
 }
 
-func splitName(fullName string) (string, string) {
-	names := strings.Fields(fullName)
-	if len(names) == 0 {
-		return "", ""
+// lockTeam serializes read-modify-write operations on the roster of a team.
+func (s *DefaultTeamService) lockTeam(id int) func() {
+	lock, _ := s.teamLocks.LoadOrStore(id, &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// removePlayerID returns the roster without any reference to the player.
+func removePlayerID(roster []int, playerID int) []int {
+	var remaining []int
+	for _, id := range roster {
+		if id != playerID {
+			remaining = append(remaining, id)
+		}
+	}
+	return remaining
+}
+
+// replacePlayerID returns the roster referencing another player instead of the player, keeping a single reference.
+func replacePlayerID(roster []int, playerID int, replacementID int) []int {
+	if slices.Contains(roster, replacementID) {
+		return removePlayerID(roster, playerID)
+	}
+	replaced := slices.Clone(roster)
+	for i, id := range replaced {
+		if id == playerID {
+			replaced[i] = replacementID
+		}
 	}
-	firstName := names[0]
-	lastName := strings.Join(names[1:], " ")
-	return firstName, lastName
+	return replaced
 }
@@ -0,0 +1,134 @@
+package thirdparty
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores the responses of a third party API for a time to live.
+type Cache interface {
+	// Get returns the value of the key, false when it is missing or expired
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration) error
+	// Len returns the number of entries, expired ones included until they are evicted
+	Len() int
+}
+
+type cacheEntry struct {
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (e cacheEntry) expired() bool {
+	return !time.Now().Before(e.ExpiresAt)
+}
+
+type InMemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewInMemoryCache() *InMemoryCache {
+	return &InMemoryCache{
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *InMemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	if entry.expired() {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+func (c *InMemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Evict the expired entries of the keys that are never read again
+	for otherKey, entry := range c.entries {
+		if entry.expired() {
+			delete(c.entries, otherKey)
+		}
+	}
+	c.entries[key] = cacheEntry{Value: value, ExpiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (c *InMemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// FileCache stores every entry in its own file of a directory, so the cache survives restarts.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache creates a cache in the directory, creating the directory when it does not exist.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	content, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil || entry.expired() {
+		os.Remove(c.path(key))
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+// Set writes the entry to a temporary file renamed over the previous one, so a reader never sees a partial entry.
+func (c *FileCache) Set(key string, value []byte, ttl time.Duration) error {
+	content, err := json.Marshal(cacheEntry{Value: value, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), c.path(key))
+}
+
+func (c *FileCache) Len() int {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	return len(paths)
+}
+
+// path names the file of a key after its hash, the key holding characters a file name cannot.
+func (c *FileCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
@@ -0,0 +1,208 @@
+package thirdparty
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"scoreplay/internal/dto"
+	"scoreplay/internal/dto/thirdparty"
+	"scoreplay/internal/exception"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachingTheSportsDBService caches the lookups of another TheSportsDBService for a time to live, and the entities it
+// does not know for a shorter one. Concurrent lookups of the same key share a single upstream call, bounded by the call
+// timeout since it outlives the callers.
+type CachingTheSportsDBService struct {
+	service     TheSportsDBService
+	cache       Cache
+	backend     string
+	ttl         time.Duration
+	negativeTTL time.Duration
+	callTimeout time.Duration
+
+	mu      sync.Mutex
+	flights map[string]*flight
+
+	hits           atomic.Int64
+	negativeHits   atomic.Int64
+	misses         atomic.Int64
+	coalesced      atomic.Int64
+	upstreamCalls  atomic.Int64
+	upstreamErrors atomic.Int64
+}
+
+// flight is an upstream call in progress, done is closed once its result is set. An optional flight fails rather than
+// wait for the quota, so only the optional calls join it.
+type flight struct {
+	done     chan struct{}
+	optional bool
+	value    []byte
+	err      error
+}
+
+// cachedLookup is the cached form of a lookup, NotFound caching the entities unknown to the API.
+type cachedLookup[T any] struct {
+	Value    T    `json:"value"`
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+func NewCachingTheSportsDBService(service TheSportsDBService, cache Cache, backend string, ttl time.Duration, negativeTTL time.Duration,
+	callTimeout time.Duration) *CachingTheSportsDBService {
+	return &CachingTheSportsDBService{
+		service:     service,
+		cache:       cache,
+		backend:     backend,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		callTimeout: callTimeout,
+		flights:     make(map[string]*flight),
+	}
+}
+
+func (s *CachingTheSportsDBService) SearchTeam(ctx context.Context, teamName string) (*thirdparty.TheSportsDBSearchTeamDto, error) {
+	return cachedCall(ctx, s, "team:"+cacheKey(teamName), "team not found", func(ctx context.Context) (*thirdparty.TheSportsDBSearchTeamDto, error) {
+		return s.service.SearchTeam(ctx, teamName)
+	})
+}
+
+func (s *CachingTheSportsDBService) SearchPlayers(ctx context.Context, teamName string) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
+	return cachedCall(ctx, s, "players:"+cacheKey(teamName), "players not found", func(ctx context.Context) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
+		return s.service.SearchPlayers(ctx, teamName)
+	})
+}
+
+func (s *CachingTheSportsDBService) LookupTeam(ctx context.Context, teamID string) (*thirdparty.TheSportsDBSearchTeamDto, error) {
+	return cachedCall(ctx, s, "team-id:"+cacheKey(teamID), "team not found", func(ctx context.Context) (*thirdparty.TheSportsDBSearchTeamDto, error) {
+		return s.service.LookupTeam(ctx, teamID)
+	})
+}
+
+func (s *CachingTheSportsDBService) LookupTeamPlayers(ctx context.Context, teamID string) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
+	return cachedCall(ctx, s, "players-id:"+cacheKey(teamID), "players not found", func(ctx context.Context) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
+		return s.service.LookupTeamPlayers(ctx, teamID)
+	})
+}
+
+func (s *CachingTheSportsDBService) LookupPlayer(ctx context.Context, playerID string) (*thirdparty.TheSportsDBSearchPlayerDto, error) {
+	return cachedCall(ctx, s, "player:"+cacheKey(playerID), "player not found", func(ctx context.Context) (*thirdparty.TheSportsDBSearchPlayerDto, error) {
+		return s.service.LookupPlayer(ctx, playerID)
+	})
+}
+
+// Stats returns the counters of the cache since the service was created.
+func (s *CachingTheSportsDBService) Stats() dto.CacheStatsDTO {
+	return dto.CacheStatsDTO{
+		Backend:        s.backend,
+		Entries:        s.cache.Len(),
+		Hits:           s.hits.Load(),
+		NegativeHits:   s.negativeHits.Load(),
+		Misses:         s.misses.Load(),
+		Coalesced:      s.coalesced.Load(),
+		UpstreamCalls:  s.upstreamCalls.Load(),
+		UpstreamErrors: s.upstreamErrors.Load(),
+	}
+}
+
+// cacheKey ignores the case and the surrounding spaces of a team name or an ID, like the API searches do.
+func cacheKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// cachedCall returns the cached lookup of the key, or joins the upstream call loading it. A cached "not found" is
+// reported with the message.
+func cachedCall[T any](ctx context.Context, s *CachingTheSportsDBService, key string, notFoundMessage string, load func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if content, ok := s.cache.Get(key); ok {
+		if lookup, err := decodeLookup[T](content); err == nil {
+			if lookup.NotFound {
+				s.negativeHits.Add(1)
+				return zero, notFound(notFoundMessage)
+			}
+			s.hits.Add(1)
+			return lookup.Value, nil
+		}
+	}
+	s.misses.Add(1)
+
+	content, err := s.join(ctx, key, func(ctx context.Context) ([]byte, error) {
+		s.upstreamCalls.Add(1)
+		value, err := load(ctx)
+		var thirdPartyException *exception.ThirdPartyException
+		if errors.As(err, &thirdPartyException) && thirdPartyException.StatusCode == http.StatusNotFound {
+			content, _ := json.Marshal(cachedLookup[T]{NotFound: true})
+			s.cache.Set(key, content, s.negativeTTL)
+			return content, nil
+		}
+		if err != nil {
+			s.upstreamErrors.Add(1)
+			return nil, err
+		}
+		content, err := json.Marshal(cachedLookup[T]{Value: value})
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Set(key, content, s.ttl)
+		return content, nil
+	})
+	if err != nil {
+		return zero, err
+	}
+	lookup, err := decodeLookup[T](content)
+	if err != nil {
+		return zero, err
+	}
+	if lookup.NotFound {
+		return zero, notFound(notFoundMessage)
+	}
+	return lookup.Value, nil
+}
+
+// join makes the upstream call of the key, or waits for the one already in progress. The call is detached from the
+// cancellation of the caller that started it, so it still serves the other callers when that one gives up, and times
+// out on its own. A required call does not join an optional one, which would fail it without waiting for the quota.
+func (s *CachingTheSportsDBService) join(ctx context.Context, key string, call func(context.Context) ([]byte, error)) ([]byte, error) {
+	optional := isOptionalCall(ctx)
+	s.mu.Lock()
+	current, inProgress := s.flights[key]
+	if inProgress && (optional || !current.optional) {
+		s.coalesced.Add(1)
+	} else {
+		current = &flight{done: make(chan struct{}), optional: optional}
+		s.flights[key] = current
+		go func() {
+			callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.callTimeout)
+			defer cancel()
+			current.value, current.err = call(callCtx)
+			s.mu.Lock()
+			// A required call may have replaced an optional one meanwhile
+			if s.flights[key] == current {
+				delete(s.flights, key)
+			}
+			s.mu.Unlock()
+			close(current.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-current.done:
+		return current.value, current.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func decodeLookup[T any](content []byte) (cachedLookup[T], error) {
+	var lookup cachedLookup[T]
+	err := json.Unmarshal(content, &lookup)
+	return lookup, err
+}
+
+func notFound(message string) *exception.ThirdPartyException {
+	return exception.NewThirdPartyException("thesportsdb", http.StatusNotFound, message)
+}
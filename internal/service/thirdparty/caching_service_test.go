@@ -0,0 +1,78 @@
+package thirdparty
+
+import (
+	"context"
+	"scoreplay/internal/dto/thirdparty"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingService answers the team lookups once released, recording the contexts of the calls.
+type blockingService struct {
+	TheSportsDBService
+	release chan struct{}
+	mu      sync.Mutex
+	calls   []context.Context
+}
+
+func (s *blockingService) LookupTeam(ctx context.Context, teamID string) (*thirdparty.TheSportsDBSearchTeamDto, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, ctx)
+	s.mu.Unlock()
+	<-s.release
+	return &thirdparty.TheSportsDBSearchTeamDto{TeamID: teamID}, nil
+}
+
+func (s *blockingService) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calls)
+}
+
+func TestCachingTheSportsDBServiceKeepsTheRequiredCallsOutOfTheOptionalOnes(t *testing.T) {
+	service := &blockingService{release: make(chan struct{})}
+	cache := NewCachingTheSportsDBService(service, NewInMemoryCache(), "memory", time.Hour, time.Minute, time.Minute)
+
+	var wg sync.WaitGroup
+	lookup := func(ctx context.Context) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.LookupTeam(ctx, "133604"); err != nil {
+				t.Errorf("LookupTeam: %v", err)
+			}
+		}()
+	}
+	waitForCalls := func(count int) {
+		for deadline := time.Now().Add(time.Second); service.callCount() < count; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("calls = %d, want %d", service.callCount(), count)
+			}
+		}
+	}
+
+	// A required lookup does not join the optional one in progress, an optional lookup joins any
+	lookup(WithOptionalCalls(context.Background()))
+	waitForCalls(1)
+	lookup(context.Background())
+	waitForCalls(2)
+	lookup(WithOptionalCalls(context.Background()))
+	lookup(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	close(service.release)
+	wg.Wait()
+
+	if stats := cache.Stats(); stats.UpstreamCalls != 2 || stats.Coalesced != 2 {
+		t.Errorf("stats = %+v, want 2 upstream calls and 2 coalesced", stats)
+	}
+	if isOptionalCall(service.calls[0]) == isOptionalCall(service.calls[1]) {
+		t.Errorf("both upstream calls are optional: %t, want the first one only", isOptionalCall(service.calls[0]))
+	}
+	// The shared calls outlive their callers, so they have a deadline of their own
+	for i, ctx := range service.calls {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("upstream call %d has no deadline", i+1)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package thirdparty
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"scoreplay/internal/dto/thirdparty"
+	"scoreplay/internal/exception"
+	"scoreplay/internal/fakesportsdb"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFakeTheSportsDBService creates a service calling a fake TheSportsDB serving the sample fixtures, the fake server
+// being returned to inject faults.
+func newFakeTheSportsDBService(t *testing.T, retry RetryPolicy, breaker *CircuitBreaker, limiter *RateLimiter) (*DefaultTheSportsBDService, *fakesportsdb.Server) {
+	t.Helper()
+	server, fake := fakesportsdb.Start(fakesportsdb.Config{})
+	t.Cleanup(server.Close)
+	t.Setenv("THESPORTSDB_API_URL", server.URL+"/api")
+	t.Setenv("THESPORTSDB_API_VERSION", "v1")
+	t.Setenv("THESPORTSDB_API_KEY", "3")
+	return NewDefaultTheSportsBDService(&http.Client{Timeout: 500 * time.Millisecond}, retry, breaker, limiter), fake
+}
+
+func TestTheSportsDBServiceAgainstTheFakeServer(t *testing.T) {
+	service, _ := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, time.Minute), nil)
+	ctx := context.Background()
+
+	team, err := service.SearchTeam(ctx, "Arsenal")
+	if err != nil {
+		t.Fatalf("SearchTeam: %v", err)
+	}
+	if team.TeamID != "133604" || team.TeamName != "Arsenal" {
+		t.Errorf("team = %s %q, want 133604 Arsenal", team.TeamID, team.TeamName)
+	}
+
+	team, err = service.LookupTeam(ctx, "133602")
+	if err != nil {
+		t.Fatalf("LookupTeam: %v", err)
+	}
+	if team.TeamName != "Liverpool" {
+		t.Errorf("team = %q, want Liverpool", team.TeamName)
+	}
+
+	players, err := service.LookupTeamPlayers(ctx, "133604")
+	if err != nil {
+		t.Fatalf("LookupTeamPlayers: %v", err)
+	}
+	if !hasPlayer(players, "Bukayo Saka") {
+		t.Errorf("players of 133604 = %v, want Bukayo Saka among them", players)
+	}
+
+	players, err = service.SearchPlayers(ctx, "liverpool")
+	if err != nil {
+		t.Fatalf("SearchPlayers: %v", err)
+	}
+	if !hasPlayer(players, "Mohamed Salah") {
+		t.Errorf("players of liverpool = %v, want Mohamed Salah among them", players)
+	}
+
+	player, err := service.LookupPlayer(ctx, "34146370")
+	if err != nil {
+		t.Fatalf("LookupPlayer: %v", err)
+	}
+	if player.PlayerName != "Martin Ødegaard" {
+		t.Errorf("player = %q, want Martin Ødegaard", player.PlayerName)
+	}
+
+	// The fake server answers an empty result for the requests it has no fixture of, like the API
+	if _, err := service.SearchTeam(ctx, "Nowhere FC"); !isThirdPartyStatus(err, http.StatusNotFound) {
+		t.Errorf("SearchTeam of an unknown team: error = %v, want a 404 ThirdPartyException", err)
+	}
+	if _, err := service.LookupPlayer(ctx, "1"); !isThirdPartyStatus(err, http.StatusNotFound) {
+		t.Errorf("LookupPlayer of an unknown player: error = %v, want a 404 ThirdPartyException", err)
+	}
+}
+
+func TestTheSportsDBServiceFaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		faults     fakesportsdb.Faults
+		retry      RetryPolicy
+		wantStatus int
+	}{
+		{
+			name:       "server errors",
+			faults:     fakesportsdb.Faults{ServerErrorRatio: 1},
+			retry:      testRetryPolicy,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "malformed responses",
+			faults:     fakesportsdb.Faults{MalformedRatio: 1},
+			retry:      testRetryPolicy,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "latency",
+			faults:     fakesportsdb.Faults{Latency: fakesportsdb.Duration(2 * time.Second)},
+			retry:      RetryPolicy{MaxAttempts: 1},
+			wantStatus: http.StatusGatewayTimeout,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, fake := newFakeTheSportsDBService(t, test.retry, NewCircuitBreaker(0, time.Minute), nil)
+			fake.SetFaults(test.faults)
+
+			if _, err := service.LookupTeam(context.Background(), "133604"); !isThirdPartyStatus(err, test.wantStatus) {
+				t.Errorf("error = %v, want a %d ThirdPartyException", err, test.wantStatus)
+			}
+
+			fake.SetFaults(fakesportsdb.Faults{})
+			if _, err := service.LookupTeam(context.Background(), "133604"); err != nil {
+				t.Errorf("LookupTeam once the faults are cleared: %v", err)
+			}
+		})
+	}
+}
+
+func TestTheSportsDBServiceRateLimitedByTheFakeServer(t *testing.T) {
+	service, fake := newFakeTheSportsDBService(t, RetryPolicy{MaxAttempts: 1}, NewCircuitBreaker(5, time.Minute), nil)
+	fake.SetFaults(fakesportsdb.Faults{RateLimitRatio: 1, RetryAfter: fakesportsdb.Duration(3 * time.Second)})
+
+	_, err := service.SearchTeam(context.Background(), "Arsenal")
+	var rateLimit exception.RateLimitException
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("error = %v, want a RateLimitException", err)
+	}
+	if rateLimit.RetryAfter != 3*time.Second {
+		t.Errorf("RetryAfter = %s, want 3s", rateLimit.RetryAfter)
+	}
+}
+
+func TestTheSportsDBServiceRetriesAfterTheDelayOfTheFakeServer(t *testing.T) {
+	service, fake := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, time.Minute), nil)
+	fake.SetFaults(fakesportsdb.Faults{RateLimitRatio: 1, RetryAfter: fakesportsdb.Duration(time.Second)})
+	time.AfterFunc(200*time.Millisecond, func() { fake.SetFaults(fakesportsdb.Faults{}) })
+
+	start := time.Now()
+	if _, err := service.SearchTeam(context.Background(), "Arsenal"); err != nil {
+		t.Fatalf("SearchTeam: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want the 1s requested", elapsed)
+	}
+}
+
+func TestTheSportsDBServiceOpensTheBreakerOnTheFakeServerErrors(t *testing.T) {
+	service, fake := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, 100*time.Millisecond), nil)
+	fake.SetFaults(fakesportsdb.Faults{ServerErrorRatio: 1})
+
+	// Three failed attempts, then two more opening the breaker before the last attempt of the second call
+	if _, err := service.LookupTeam(context.Background(), "133604"); !isThirdPartyStatus(err, http.StatusInternalServerError) {
+		t.Fatalf("first call: error = %v, want a 500 ThirdPartyException", err)
+	}
+	_, err := service.LookupTeam(context.Background(), "133604")
+	if !isThirdPartyStatus(err, http.StatusServiceUnavailable) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call: error = %v, want a 503 ThirdPartyException caused by the open breaker", err)
+	}
+
+	fake.SetFaults(fakesportsdb.Faults{})
+	time.Sleep(150 * time.Millisecond)
+	if _, err := service.LookupTeam(context.Background(), "133604"); err != nil {
+		t.Errorf("LookupTeam after the cooldown: %v", err)
+	}
+}
+
+func TestCachingTheSportsDBServiceAgainstTheFakeServer(t *testing.T) {
+	service, fake := newFakeTheSportsDBService(t, RetryPolicy{MaxAttempts: 1}, NewCircuitBreaker(0, time.Minute), nil)
+	cache := NewCachingTheSportsDBService(service, NewInMemoryCache(), "memory", time.Hour, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	for _, name := range []string{"Arsenal", "arsenal", " ARSENAL "} {
+		team, err := cache.SearchTeam(ctx, name)
+		if err != nil {
+			t.Fatalf("SearchTeam(%q): %v", name, err)
+		}
+		if team.TeamID != "133604" {
+			t.Errorf("SearchTeam(%q) = %s, want 133604", name, team.TeamID)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.SearchTeam(ctx, "Nowhere FC"); !isThirdPartyStatus(err, http.StatusNotFound) {
+			t.Errorf("SearchTeam of an unknown team: error = %v, want a 404 ThirdPartyException", err)
+		}
+	}
+	stats := cache.Stats()
+	if stats.UpstreamCalls != 2 || stats.Hits != 2 || stats.NegativeHits != 1 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("stats = %+v, want 2 upstream calls, 2 hits, 1 negative hit, 2 misses and 2 entries", stats)
+	}
+
+	// The cached lookups are served while the API fails, and the failures are not cached
+	fake.SetFaults(fakesportsdb.Faults{ServerErrorRatio: 1})
+	if _, err := cache.SearchTeam(ctx, "Arsenal"); err != nil {
+		t.Errorf("cached SearchTeam while the API fails: %v", err)
+	}
+	if _, err := cache.LookupTeam(ctx, "133602"); !isThirdPartyStatus(err, http.StatusInternalServerError) {
+		t.Errorf("LookupTeam while the API fails: error = %v, want a 500 ThirdPartyException", err)
+	}
+	fake.SetFaults(fakesportsdb.Faults{MalformedRatio: 1})
+	if _, err := cache.LookupTeam(ctx, "133602"); !isThirdPartyStatus(err, http.StatusInternalServerError) {
+		t.Errorf("LookupTeam of a malformed response: error = %v, want a 500 ThirdPartyException", err)
+	}
+	fake.SetFaults(fakesportsdb.Faults{})
+	if _, err := cache.LookupTeam(ctx, "133602"); err != nil {
+		t.Errorf("LookupTeam once the API recovered: %v", err)
+	}
+	stats = cache.Stats()
+	if stats.UpstreamCalls != 5 || stats.UpstreamErrors != 2 || stats.Entries != 3 {
+		t.Errorf("stats = %+v, want 5 upstream calls, 2 upstream errors and 3 entries", stats)
+	}
+}
+
+func TestCachingTheSportsDBServiceCoalescesTheCallsToTheFakeServer(t *testing.T) {
+	service, fake := newFakeTheSportsDBService(t, RetryPolicy{MaxAttempts: 1}, NewCircuitBreaker(0, time.Minute), nil)
+	cache := NewCachingTheSportsDBService(service, NewInMemoryCache(), "memory", time.Hour, time.Minute, time.Minute)
+	fake.SetFaults(fakesportsdb.Faults{Latency: fakesportsdb.Duration(200 * time.Millisecond)})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			players, err := cache.LookupTeamPlayers(context.Background(), "133604")
+			if err == nil && !hasPlayer(players, "William Saliba") {
+				err = errors.New("William Saliba missing from the players")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("LookupTeamPlayers: %v", err)
+		}
+	}
+	if stats := cache.Stats(); stats.UpstreamCalls != 1 || stats.Coalesced != callers-1 {
+		t.Errorf("stats = %+v, want 1 upstream call and %d coalesced", stats, callers-1)
+	}
+}
+
+func TestRateLimiterAgainstTheFakeServer(t *testing.T) {
+	t.Run("waits for the tokens", func(t *testing.T) {
+		// A token every 50ms after a burst of 1
+		limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 1200, Burst: 1}, time.Second)
+		service, _ := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, time.Minute), limiter)
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			if _, err := service.LookupPlayer(context.Background(), "34161043"); err != nil {
+				t.Fatalf("LookupPlayer: %v", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+			t.Errorf("4 calls took %s, want at least 150ms", elapsed)
+		}
+	})
+
+	t.Run("fails the calls that would wait too long", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 60, Burst: 2}, 100*time.Millisecond)
+		service, _ := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, time.Minute), limiter)
+
+		for i := 0; i < 2; i++ {
+			if _, err := service.LookupTeam(context.Background(), "133604"); err != nil {
+				t.Fatalf("LookupTeam within the burst: %v", err)
+			}
+		}
+		_, err := service.LookupTeam(context.Background(), "133604")
+		var rateLimit exception.RateLimitException
+		if !errors.As(err, &rateLimit) {
+			t.Fatalf("error = %v, want a RateLimitException", err)
+		}
+		if rateLimit.RetryAfter <= 100*time.Millisecond || rateLimit.RetryAfter > time.Second {
+			t.Errorf("RetryAfter = %s, want the wait for the next token, up to 1s", rateLimit.RetryAfter)
+		}
+	})
+
+	t.Run("fails the optional calls without waiting", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 60, Burst: 1}, time.Minute)
+		service, _ := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, time.Minute), limiter)
+
+		if _, err := service.SearchTeam(context.Background(), "Liverpool"); err != nil {
+			t.Fatalf("SearchTeam: %v", err)
+		}
+		start := time.Now()
+		_, err := service.SearchTeam(WithOptionalCalls(context.Background()), "Liverpool")
+		var rateLimit exception.RateLimitException
+		if !errors.As(err, &rateLimit) {
+			t.Fatalf("error = %v, want a RateLimitException", err)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("the optional call failed after %s, want no wait", elapsed)
+		}
+	})
+
+	t.Run("takes a token per attempt", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 1, Burst: 3}, 0)
+		service, fake := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(0, time.Minute), limiter)
+		fake.SetFaults(fakesportsdb.Faults{ServerErrorRatio: 1})
+
+		if _, err := service.LookupTeam(context.Background(), "133604"); !isThirdPartyStatus(err, http.StatusInternalServerError) {
+			t.Fatalf("error = %v, want a 500 ThirdPartyException after 3 attempts", err)
+		}
+		fake.SetFaults(fakesportsdb.Faults{})
+		var rateLimit exception.RateLimitException
+		if _, err := service.LookupTeam(context.Background(), "133604"); !errors.As(err, &rateLimit) {
+			t.Errorf("error = %v, want a RateLimitException once the retries used the burst", err)
+		}
+	})
+
+	t.Run("leaves the tokens to the cache misses", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 1, Burst: 1}, 0)
+		service, _ := newFakeTheSportsDBService(t, testRetryPolicy, NewCircuitBreaker(5, time.Minute), limiter)
+		cache := NewCachingTheSportsDBService(service, NewInMemoryCache(), "memory", time.Hour, time.Minute, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			if _, err := cache.LookupTeam(context.Background(), "133604"); err != nil {
+				t.Fatalf("LookupTeam: %v", err)
+			}
+		}
+		var rateLimit exception.RateLimitException
+		if _, err := cache.LookupTeam(context.Background(), "133602"); !errors.As(err, &rateLimit) {
+			t.Errorf("error = %v, want a RateLimitException for the second team", err)
+		}
+		if stats := cache.Stats(); stats.UpstreamCalls != 2 || stats.Hits != 2 || stats.UpstreamErrors != 1 {
+			t.Errorf("stats = %+v, want 2 upstream calls, 2 hits and 1 upstream error", stats)
+		}
+	})
+}
+
+func hasPlayer(players []thirdparty.TheSportsDBSearchPlayerDto, name string) bool {
+	return slices.ContainsFunc(players, func(player thirdparty.TheSportsDBSearchPlayerDto) bool {
+		return player.PlayerName == name
+	})
+}
+
+func isThirdPartyStatus(err error, status int) bool {
+	var thirdParty *exception.ThirdPartyException
+	return errors.As(err, &thirdParty) && thirdParty.StatusCode == status
+}
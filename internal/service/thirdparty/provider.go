@@ -0,0 +1,67 @@
+package thirdparty
+
+import (
+	"context"
+	"fmt"
+	"scoreplay/internal/dto/thirdparty"
+	"scoreplay/internal/exception"
+	"sort"
+)
+
+// SportsDataProvider looks up teams and players on a sports data API, whatever its own data model.
+type SportsDataProvider interface {
+	// Name identifies the provider in the configuration and in the teams it is authoritative for
+	Name() string
+	LookupTeam(ctx context.Context, teamName string) (*thirdparty.ProviderTeamDto, error)
+	LookupSquad(ctx context.Context, teamName string) ([]thirdparty.ProviderPlayerDto, error)
+	// LookupTeamByID and LookupSquadByTeamID look up a team by its ID at the provider, which unlike its name is unambiguous
+	LookupTeamByID(ctx context.Context, externalID string) (*thirdparty.ProviderTeamDto, error)
+	LookupSquadByTeamID(ctx context.Context, teamExternalID string) ([]thirdparty.ProviderPlayerDto, error)
+	LookupPlayer(ctx context.Context, externalID string) (*thirdparty.ProviderPlayerDto, error)
+}
+
+// ProviderRegistry holds the enabled providers, one of them being the default of the teams that do not set theirs.
+type ProviderRegistry struct {
+	providers       map[string]SportsDataProvider
+	defaultProvider string
+}
+
+// NewProviderRegistry creates a registry of the providers, failing when the default one is not among them.
+func NewProviderRegistry(defaultProvider string, providers ...SportsDataProvider) (*ProviderRegistry, error) {
+	registry := &ProviderRegistry{
+		providers:       make(map[string]SportsDataProvider),
+		defaultProvider: defaultProvider,
+	}
+	for _, provider := range providers {
+		if _, duplicated := registry.providers[provider.Name()]; duplicated {
+			return nil, fmt.Errorf("provider %q registered twice", provider.Name())
+		}
+		registry.providers[provider.Name()] = provider
+	}
+	if _, exists := registry.providers[defaultProvider]; !exists {
+		return nil, fmt.Errorf("default provider %q is not registered", defaultProvider)
+	}
+	return registry, nil
+}
+
+// Get returns the provider of the name, the default one when the name is empty.
+func (r *ProviderRegistry) Get(name string) (SportsDataProvider, error) {
+	if name == "" {
+		name = r.defaultProvider
+	}
+	provider, exists := r.providers[name]
+	if !exists {
+		return nil, exception.InvalidArgumentException{Argument: "provider", Reason: fmt.Sprintf("unknown provider %q", name)}
+	}
+	return provider, nil
+}
+
+// Names returns the names of the registered providers, sorted.
+func (r *ProviderRegistry) Names() []string {
+	var names []string
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,131 @@
+package thirdparty
+
+import (
+	"context"
+	"fmt"
+	"scoreplay/internal/exception"
+	"sync"
+	"time"
+)
+
+// RateLimit is a request quota, Burst requests being allowed at once.
+type RateLimit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// rateLimitTiers are the quotas of TheSportsDB API key tiers.
+var rateLimitTiers = map[string]RateLimit{
+	"free":    {RequestsPerMinute: 30, Burst: 5},
+	"premium": {RequestsPerMinute: 100, Burst: 10},
+}
+
+// RateLimitForTier returns the quota of an API key tier, the free one when empty.
+func RateLimitForTier(tier string) (RateLimit, error) {
+	if tier == "" {
+		tier = "free"
+	}
+	limit, exists := rateLimitTiers[tier]
+	if !exists {
+		return RateLimit{}, fmt.Errorf("unknown API key tier %q", tier)
+	}
+	return limit, nil
+}
+
+// optionalCallKey marks the contexts of the calls that are not worth waiting for the quota.
+type optionalCallKey struct{}
+
+// WithOptionalCalls marks the calls made with the context as optional, failing with a RateLimitException when the
+// quota is exhausted instead of waiting for it, to leave the quota to the calls that matter.
+func WithOptionalCalls(ctx context.Context) context.Context {
+	return context.WithValue(ctx, optionalCallKey{}, true)
+}
+
+// isOptionalCall tells if the context was marked by WithOptionalCalls.
+func isOptionalCall(ctx context.Context) bool {
+	optional, _ := ctx.Value(optionalCallKey{}).(bool)
+	return optional
+}
+
+// RateLimiter is a token bucket shared by the calls to an API. A call waits for a token rather than failing, unless
+// it would wait longer than the maximum wait or the deadline of its context.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	maxWait time.Duration
+}
+
+// NewRateLimiter creates a full bucket, nil when the limit has no rate so calls are not limited.
+func NewRateLimiter(limit RateLimit, maxWait time.Duration) *RateLimiter {
+	if limit.RequestsPerMinute <= 0 {
+		return nil
+	}
+	burst := float64(max(limit.Burst, 1))
+	return &RateLimiter{
+		rate:    float64(limit.RequestsPerMinute) / 60,
+		burst:   burst,
+		tokens:  burst,
+		last:    time.Now(),
+		maxWait: maxWait,
+	}
+}
+
+// Wait takes a token, waiting until one is available. It fails with a RateLimitException without waiting when the
+// token would come too late.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	delay, err := l.reserve(ctx)
+	if err != nil || delay == 0 {
+		return err
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token in advance, returning how long to wait for it.
+func (l *RateLimiter) reserve(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	delay := time.Duration(0)
+	if l.tokens < 1 {
+		delay = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	maxWait := l.maxWait
+	if isOptionalCall(ctx) {
+		maxWait = 0
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = min(maxWait, time.Until(deadline))
+	}
+	if delay > maxWait {
+		return 0, exception.RateLimitException{Source: "thesportsdb", RetryAfter: delay}
+	}
+	l.tokens--
+	return delay, nil
+}
+
+// release gives back a token reserved by a call that gave up waiting.
+func (l *RateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
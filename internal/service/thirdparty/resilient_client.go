@@ -0,0 +1,210 @@
+package thirdparty
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// RetryPolicy retries the calls failing with a network error, a 5xx or a 429, waiting an exponential backoff with
+// full jitter between the attempts, or the delay of the Retry-After header when the API sends one.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy makes three attempts, waiting up to 200ms then 400ms.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// backoff returns the delay before the next attempt, the first retry being attempt 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := attempt - 1; shift < 30 && p.BaseDelay<<shift < p.MaxDelay {
+		delay = p.BaseDelay << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// retryAfter reads the delay requested by the Retry-After header, capped at the maximum delay.
+func (p RetryPolicy) retryAfter(resp *http.Response) (time.Duration, bool) {
+	delay, ok := parseRetryAfter(resp)
+	return min(delay, p.MaxDelay), ok
+}
+
+// parseRetryAfter reads the delay requested by the Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+	return max(delay, 0), true
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreaker fails fast once the API failed threshold times in a row. After the cooldown a single trial call is let
+// through, closing the circuit when it succeeds and opening it again when it fails.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker, a threshold of 0 or less disables it.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow tells whether a call can be made, letting a single trial call through once the cooldown has elapsed.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = circuitClosed
+	b.failures = 0
+}
+
+func (b *CircuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// abandon gives the trial call back when it was canceled before the API answered.
+func (b *CircuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.state = circuitOpen
+	}
+}
+
+// resilientClient sends GET requests with the retry policy, behind the circuit breaker, every attempt taking a token
+// of the rate limiter.
+type resilientClient struct {
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+	limiter *RateLimiter
+}
+
+// get returns the response of the last attempt, whatever its status, or the error of the last attempt.
+func (c *resilientClient) get(ctx context.Context, url string) (*http.Response, error) {
+	attempts := max(c.retry.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		if !c.breaker.allow() {
+			return nil, ErrCircuitOpen
+		}
+		if err := c.limiter.Wait(ctx); err != nil {
+			c.breaker.abandon()
+			return nil, err
+		}
+		resp, err := c.do(ctx, url)
+		if ctx.Err() != nil {
+			// The caller gave up, it is not a failure of the API
+			c.breaker.abandon()
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+			c.breaker.failure()
+		} else {
+			c.breaker.success()
+		}
+		if !retryable || attempt >= attempts {
+			return resp, err
+		}
+
+		delay := c.retry.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := c.retry.retryAfter(resp); ok {
+				delay = retryAfter
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *resilientClient) do(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Do(req)
+}
@@ -0,0 +1,331 @@
+package thirdparty
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly, so the tests only wait for the delays they ask for.
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+
+// statusServer answers every request with the next status of the list, the last one once the list is exhausted, and
+// counts the requests.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		w.WriteHeader(statuses[min(call, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestClient(retry RetryPolicy, breaker *CircuitBreaker) *resilientClient {
+	return &resilientClient{client: &http.Client{Timeout: 5 * time.Second}, retry: retry, breaker: breaker}
+}
+
+func TestResilientClientRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "success", statuses: []int{200}, wantStatus: 200, wantCalls: 1},
+		{name: "server error then success", statuses: []int{500, 200}, wantStatus: 200, wantCalls: 2},
+		{name: "unavailable twice then success", statuses: []int{503, 502, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "rate limited then success", statuses: []int{429, 200}, wantStatus: 200, wantCalls: 2},
+		{name: "server errors until the last attempt", statuses: []int{500}, wantStatus: 500, wantCalls: 3},
+		{name: "rate limited until the last attempt", statuses: []int{429}, wantStatus: 429, wantCalls: 3},
+		{name: "not found is not retried", statuses: []int{404, 200}, wantStatus: 404, wantCalls: 1},
+		{name: "bad request is not retried", statuses: []int{400, 200}, wantStatus: 400, wantCalls: 1},
+		{name: "unauthorized is not retried", statuses: []int{401, 200}, wantStatus: 401, wantCalls: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, calls := statusServer(t, test.statuses...)
+			client := newTestClient(testRetryPolicy, NewCircuitBreaker(0, 0))
+
+			resp, err := client.get(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if calls.Load() != test.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResilientClientRetriesNetworkErrors(t *testing.T) {
+	server, _ := statusServer(t, 200)
+	server.Close()
+	client := newTestClient(testRetryPolicy, NewCircuitBreaker(0, 0))
+
+	resp, err := client.get(context.Background(), server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("get succeeded on a closed server")
+	}
+}
+
+func TestResilientClientHonorsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxDelay   time.Duration
+		minWait    time.Duration
+		maxWait    time.Duration
+	}{
+		{name: "seconds", retryAfter: "1", maxDelay: 2 * time.Second, minWait: time.Second, maxWait: 1900 * time.Millisecond},
+		{name: "HTTP date", retryAfter: time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat), maxDelay: 5 * time.Second,
+			minWait: time.Second, maxWait: 4 * time.Second},
+		{name: "capped at the maximum delay", retryAfter: "60", maxDelay: 100 * time.Millisecond, minWait: 100 * time.Millisecond,
+			maxWait: time.Second},
+		{name: "malformed", retryAfter: "soon", maxDelay: time.Millisecond, maxWait: 500 * time.Millisecond},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.Header().Set("Retry-After", test.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}))
+			defer server.Close()
+			retry := testRetryPolicy
+			retry.MaxDelay = test.maxDelay
+			client := newTestClient(retry, NewCircuitBreaker(0, 0))
+
+			start := time.Now()
+			resp, err := client.get(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			resp.Body.Close()
+			waited := time.Since(start)
+			if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+				t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+			}
+			if waited < test.minWait || waited > test.maxWait {
+				t.Errorf("waited %s, want between %s and %s", waited, test.minWait, test.maxWait)
+			}
+		})
+	}
+}
+
+func TestResilientClientStopsWaitingWhenCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	retry := testRetryPolicy
+	retry.MaxDelay = 10 * time.Second
+	client := newTestClient(retry, NewCircuitBreaker(1, time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.get(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("get = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if waited := time.Since(start); waited > 2*time.Second {
+		t.Errorf("waited %s after the deadline", waited)
+	}
+	// A 429 is not a failure of the API, the breaker stays closed
+	if !client.breaker.allow() {
+		t.Errorf("breaker opened by rate limited calls")
+	}
+}
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+	cooldown := 100 * time.Millisecond
+	client := newTestClient(RetryPolicy{MaxAttempts: 1}, NewCircuitBreaker(2, cooldown))
+
+	get := func() (int, error) {
+		resp, err := client.get(context.Background(), server.URL)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// Two failures in a row trip the breaker, which then fails fast without calling the API
+	for i := 0; i < 2; i++ {
+		if code, err := get(); err != nil || code != http.StatusInternalServerError {
+			t.Fatalf("call %d = %d, %v, want 500", i+1, code, err)
+		}
+	}
+	if _, err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("get = %v, want %v", err, ErrCircuitOpen)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2 while the breaker is open", calls.Load())
+	}
+
+	// After the cooldown a failed trial call opens the breaker again
+	time.Sleep(cooldown + 20*time.Millisecond)
+	if code, err := get(); err != nil || code != http.StatusInternalServerError {
+		t.Fatalf("trial call = %d, %v, want 500", code, err)
+	}
+	if _, err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("get after the failed trial = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	// A successful trial call closes it
+	status.Store(http.StatusOK)
+	time.Sleep(cooldown + 20*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if code, err := get(); err != nil || code != http.StatusOK {
+			t.Fatalf("call %d after recovery = %d, %v, want 200", i+1, code, err)
+		}
+	}
+	if calls.Load() != 6 {
+		t.Errorf("calls = %d, want 6", calls.Load())
+	}
+}
+
+func TestCircuitBreakerLetsOneTrialCallThrough(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		<-release
+	}))
+	defer server.Close()
+	cooldown := 50 * time.Millisecond
+	client := newTestClient(RetryPolicy{MaxAttempts: 1}, NewCircuitBreaker(1, cooldown))
+
+	resp, err := client.get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	time.Sleep(cooldown + 20*time.Millisecond)
+
+	// The trial call hangs until released, the other calls fail fast meanwhile
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, err := client.get(context.Background(), server.URL)
+		if err != nil {
+			t.Errorf("trial call: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	for calls.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := client.get(context.Background(), server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("get during the trial = %v, want %v", err, ErrCircuitOpen)
+	}
+	close(release)
+	wg.Wait()
+
+	if !client.breaker.allow() {
+		t.Errorf("breaker still open after a successful trial")
+	}
+}
+
+func TestCircuitBreakerGivesBackAbandonedTrial(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+	cooldown := 50 * time.Millisecond
+	client := newTestClient(RetryPolicy{MaxAttempts: 1}, NewCircuitBreaker(1, cooldown))
+
+	resp, err := client.get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	time.Sleep(cooldown + 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.get(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("trial call = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// The abandoned trial is neither a failure nor a success, the next call is a new trial
+	resp, err = client.get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("new trial call: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("new trial call status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond,
+		4: 800 * time.Millisecond, 5: time.Second, 40: time.Second} {
+		for i := 0; i < 100; i++ {
+			if delay := policy.backoff(attempt); delay < 0 || delay > ceiling {
+				t.Fatalf("backoff(%d) = %s, want at most %s", attempt, delay, ceiling)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{header: "", ok: false},
+		{header: "2", want: 2 * time.Second, ok: true},
+		{header: "0", want: 0, ok: true},
+		{header: "-5", want: 0, ok: true},
+		{header: "later", ok: false},
+		{header: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), want: 0, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(strconv.Quote(test.header), func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if test.header != "" {
+				resp.Header.Set("Retry-After", test.header)
+			}
+			delay, ok := parseRetryAfter(resp)
+			if delay != test.want || ok != test.ok {
+				t.Errorf("parseRetryAfter = %s, %t, want %s, %t", delay, ok, test.want, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package thirdparty
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"regexp"
+	"scoreplay/internal/dto/thirdparty"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TheSportsDBProviderName identifies TheSportsDB among the providers.
+const TheSportsDBProviderName = "thesportsdb"
+
+// TheSportsDBProvider adapts TheSportsDBService to the SportsDataProvider interface.
+type TheSportsDBProvider struct {
+	service TheSportsDBService
+}
+
+func NewTheSportsDBProvider(service TheSportsDBService) *TheSportsDBProvider {
+	return &TheSportsDBProvider{
+		service: service,
+	}
+}
+
+func (p *TheSportsDBProvider) Name() string {
+	return TheSportsDBProviderName
+}
+
+func (p *TheSportsDBProvider) LookupTeam(ctx context.Context, teamName string) (*thirdparty.ProviderTeamDto, error) {
+	team, err := p.service.SearchTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return mapTheSportsDBTeam(*team), nil
+}
+
+func (p *TheSportsDBProvider) LookupSquad(ctx context.Context, teamName string) ([]thirdparty.ProviderPlayerDto, error) {
+	players, err := p.service.SearchPlayers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return mapTheSportsDBSquad(players), nil
+}
+
+func (p *TheSportsDBProvider) LookupTeamByID(ctx context.Context, externalID string) (*thirdparty.ProviderTeamDto, error) {
+	team, err := p.service.LookupTeam(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+	return mapTheSportsDBTeam(*team), nil
+}
+
+func (p *TheSportsDBProvider) LookupSquadByTeamID(ctx context.Context, teamExternalID string) ([]thirdparty.ProviderPlayerDto, error) {
+	players, err := p.service.LookupTeamPlayers(ctx, teamExternalID)
+	if err != nil {
+		return nil, err
+	}
+	return mapTheSportsDBSquad(players), nil
+}
+
+func (p *TheSportsDBProvider) LookupPlayer(ctx context.Context, externalID string) (*thirdparty.ProviderPlayerDto, error) {
+	player, err := p.service.LookupPlayer(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+	providerPlayer := mapTheSportsDBPlayer(*player)
+	return &providerPlayer, nil
+}
+
+func mapTheSportsDBTeam(team thirdparty.TheSportsDBSearchTeamDto) *thirdparty.ProviderTeamDto {
+	formedYear, _ := strconv.Atoi(team.FormedYear)
+	stadiumCapacity, _ := strconv.Atoi(team.StadiumCapacity)
+	socialLinks := make(map[string]string)
+	for network, link := range map[string]string{
+		"facebook":  team.Facebook,
+		"twitter":   team.Twitter,
+		"instagram": team.Instagram,
+		"youtube":   team.Youtube,
+	} {
+		if link != "" {
+			socialLinks[network] = link
+		}
+	}
+	if len(socialLinks) == 0 {
+		socialLinks = nil
+	}
+	return &thirdparty.ProviderTeamDto{
+		ExternalID:      team.TeamID,
+		Name:            team.TeamName,
+		Logo:            team.TeamLogo,
+		ShortName:       team.TeamShort,
+		AlternateNames:  nonEmpty(strings.Split(team.Alternate, ",")...),
+		FormedYear:      formedYear,
+		Sport:           team.Sport,
+		League:          team.League,
+		Country:         team.Country,
+		Stadium:         team.Stadium,
+		StadiumCapacity: stadiumCapacity,
+		Colors:          nonEmpty(team.Colour1, team.Colour2, team.Colour3),
+		Badge:           team.TeamBadge,
+		Banner:          team.TeamBanner,
+		Jersey:          team.TeamJersey,
+		Fanart:          nonEmpty(team.TeamFanart1, team.TeamFanart2, team.TeamFanart3, team.TeamFanart4),
+		Website:         team.Website,
+		SocialLinks:     socialLinks,
+		Description:     team.DescriptionEN,
+	}
+}
+
+// nonEmpty returns the values that are not empty, nil when there is none.
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func mapTheSportsDBSquad(players []thirdparty.TheSportsDBSearchPlayerDto) []thirdparty.ProviderPlayerDto {
+	squad := make([]thirdparty.ProviderPlayerDto, 0, len(players))
+	for _, player := range players {
+		squad = append(squad, mapTheSportsDBPlayer(player))
+	}
+	return squad
+}
+
+func mapTheSportsDBPlayer(player thirdparty.TheSportsDBSearchPlayerDto) thirdparty.ProviderPlayerDto {
+	shirtNumber, _ := strconv.Atoi(strings.TrimSpace(player.Number))
+	dateOfBirth := strings.TrimSpace(player.DateBorn)
+	if _, err := time.Parse(time.DateOnly, dateOfBirth); err != nil {
+		dateOfBirth = ""
+	}
+	return thirdparty.ProviderPlayerDto{
+		ExternalID:    player.PlayerID,
+		Name:          player.PlayerName,
+		Thumbnail:     absoluteURL(player.Thumbnail),
+		Position:      strings.TrimSpace(player.Position),
+		ShirtNumber:   inRange(shirtNumber, 1, 99),
+		Nationality:   strings.TrimSpace(player.Nationality),
+		DateOfBirth:   dateOfBirth,
+		Height:        inRange(parseHeight(player.Height), 100, 250),
+		Weight:        inRange(parseWeight(player.Weight), 30, 200),
+		PreferredFoot: oneOf(player.Side, "left", "right", "both"),
+		Status:        playerStatus(player.Status),
+		Cutout:        absoluteURL(player.Cutout),
+		Render:        absoluteURL(player.Render),
+	}
+}
+
+// inRange returns the value when it is within the bounds the API accepts for it, 0 otherwise, so a misread or
+// mistyped value is left out like a missing one.
+func inRange(value int, min int, max int) int {
+	if value < min || value > max {
+		return 0
+	}
+	return value
+}
+
+// absoluteURL returns the link when it is an absolute URL, empty otherwise.
+func absoluteURL(link string) string {
+	link = strings.TrimSpace(link)
+	if parsed, err := url.Parse(link); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+	return link
+}
+
+// measureNumbers matches the numbers of a measure, like 1.85 in "1.85 m" or 6 and 1 in "6 ft 1 in".
+var measureNumbers = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+// parseHeight converts a height in meters, centimeters, or feet and inches, like "1.85 m", "185 cm" or "6 ft 1 in", to
+// centimeters, 0 when it cannot be read.
+func parseHeight(height string) int {
+	numbers := parseMeasure(height)
+	switch {
+	case len(numbers) == 0:
+		return 0
+	case strings.Contains(height, "ft") || strings.Contains(height, "'"):
+		inches := numbers[0] * 12
+		if len(numbers) > 1 {
+			inches += numbers[1]
+		}
+		return int(math.Round(inches * 2.54))
+	case numbers[0] < 3:
+		return int(math.Round(numbers[0] * 100))
+	default:
+		return int(math.Round(numbers[0]))
+	}
+}
+
+// parseWeight converts a weight in kilograms or pounds, like "80 kg" or "176 lbs", to kilograms, 0 when it cannot be read.
+func parseWeight(weight string) int {
+	numbers := parseMeasure(weight)
+	switch {
+	case len(numbers) == 0:
+		return 0
+	case strings.Contains(strings.ToLower(weight), "lb"):
+		return int(math.Round(numbers[0] * 0.45359237))
+	default:
+		return int(math.Round(numbers[0]))
+	}
+}
+
+func parseMeasure(measure string) []float64 {
+	var numbers []float64
+	for _, match := range measureNumbers.FindAllString(measure, -1) {
+		number, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+		if err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// playerStatus reads the status of a player, active, retired or loaned, empty when unknown.
+func playerStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if strings.Contains(status, "loan") {
+		return "loaned"
+	}
+	return oneOf(status, "active", "retired")
+}
+
+// oneOf returns the value lowercased when it is one of the allowed ones, empty otherwise.
+func oneOf(value string, allowed ...string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if slices.Contains(allowed, value) {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package thirdparty
+
+import (
+	"scoreplay/internal/dto/thirdparty"
+	"testing"
+)
+
+func TestMapTheSportsDBPlayerLeavesOutTheValuesTheAPIRejects(t *testing.T) {
+	player := mapTheSportsDBPlayer(thirdparty.TheSportsDBSearchPlayerDto{
+		PlayerID:   "34146370",
+		PlayerName: "Martin Ødegaard",
+		Thumbnail:  "images/thumb.png",
+		Number:     "108",
+		DateBorn:   "1998-12-17",
+		Height:     "17.8 m",
+		Weight:     "1 kg",
+		Side:       "Left",
+		Cutout:     "https://www.thesportsdb.com/images/media/player/cutout/odegaard.png",
+		Render:     "not a link",
+	})
+	want := thirdparty.ProviderPlayerDto{
+		ExternalID:    "34146370",
+		Name:          "Martin Ødegaard",
+		DateOfBirth:   "1998-12-17",
+		PreferredFoot: "left",
+		Cutout:        "https://www.thesportsdb.com/images/media/player/cutout/odegaard.png",
+	}
+	if player != want {
+		t.Errorf("player = %+v, want %+v", player, want)
+	}
+
+	player = mapTheSportsDBPlayer(thirdparty.TheSportsDBSearchPlayerDto{Number: "8", Height: "1.78 m", Weight: "68 kg"})
+	if player.ShirtNumber != 8 || player.Height != 178 || player.Weight != 68 {
+		t.Errorf("shirt number, height and weight = %d, %d and %d, want 8, 178 and 68", player.ShirtNumber, player.Height, player.Weight)
+	}
+}
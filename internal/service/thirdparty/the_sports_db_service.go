@@ -1,68 +1,151 @@
 package thirdparty
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"scoreplay/internal/dto/thirdparty"
 	"scoreplay/internal/exception"
+	"strings"
 )
 
 type DefaultTheSportsBDService struct {
 	apiURL     string
 	apiVersion string
 	apiKey     string
+	client     *resilientClient
 }
 
-func NewDefaultTheSportsBDService() *DefaultTheSportsBDService {
+// NewDefaultTheSportsBDService creates the service calling TheSportsDB through the HTTP client, retrying the failed
+// calls with the retry policy, failing fast while the circuit breaker is open and keeping to the quota of the limiter.
+func NewDefaultTheSportsBDService(client *http.Client, retry RetryPolicy, breaker *CircuitBreaker, limiter *RateLimiter) *DefaultTheSportsBDService {
 	return &DefaultTheSportsBDService{
 		apiURL:     os.Getenv("THESPORTSDB_API_URL"),
 		apiVersion: os.Getenv("THESPORTSDB_API_VERSION"),
 		apiKey:     os.Getenv("THESPORTSDB_API_KEY"),
+		client: &resilientClient{
+			client:  client,
+			retry:   retry,
+			breaker: breaker,
+			limiter: limiter,
+		},
 	}
 }
 
-func (s *DefaultTheSportsBDService) SearchTeam(teamName string) (*thirdparty.TheSportsDBSearchTeamDto, error) {
-	url := fmt.Sprintf("%s/%s/json/%s/searchteams.php?t=%s", s.apiURL, s.apiVersion, s.apiKey, teamName)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, exception.NewThirdPartyException("thesportsdb", http.StatusInternalServerError, "failed to call API")
+func (s *DefaultTheSportsBDService) SearchTeam(ctx context.Context, teamName string) (*thirdparty.TheSportsDBSearchTeamDto, error) {
+	var result thirdparty.TheSportsDBSearchTeamResponseDto
+	if err := s.fetch(ctx, "searchteams.php", url.Values{"t": {teamName}}, "failed to search team", &result); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, exception.NewThirdPartyException("thesportsdb", resp.StatusCode, "failed to search team")
+	// Clubs can share a name, like "Arsenal" and "Arsenal Tula", so the one named exactly is preferred
+	for i, team := range result.Teams {
+		if strings.EqualFold(team.TeamName, teamName) {
+			return &result.Teams[i], nil
+		}
+	}
+	if len(result.Teams) > 0 {
+		return &result.Teams[0], nil
+	}
+
+	return nil, notFound("team not found")
+}
+
+func (s *DefaultTheSportsBDService) SearchPlayers(ctx context.Context, teamName string) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
+	var result thirdparty.TheSportsDBSearchPlayerResponseDto
+	if err := s.fetch(ctx, "searchplayers.php", url.Values{"t": {teamName}}, "failed to search team "+teamName+" players", &result); err != nil {
+		return nil, err
 	}
+	return result.Players, nil
+}
 
+func (s *DefaultTheSportsBDService) LookupTeam(ctx context.Context, teamID string) (*thirdparty.TheSportsDBSearchTeamDto, error) {
 	var result thirdparty.TheSportsDBSearchTeamResponseDto
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, exception.NewThirdPartyException("thesportsdb", http.StatusInternalServerError, "failed to parse response")
+	if err := s.fetch(ctx, "lookupteam.php", url.Values{"id": {teamID}}, "failed to look up team", &result); err != nil {
+		return nil, err
 	}
 
 	if len(result.Teams) > 0 {
 		return &result.Teams[0], nil
 	}
 
-	return nil, exception.NewThirdPartyException("thesportsdb", http.StatusNotFound, "team not found")
+	return nil, notFound("team not found")
 }
 
-func (s *DefaultTheSportsBDService) SearchPlayers(teamName string) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
-	url := fmt.Sprintf("%s/%s/json/%s/searchplayers.php?t=%s", s.apiURL, s.apiVersion, s.apiKey, teamName)
-	resp, err := http.Get(url)
+func (s *DefaultTheSportsBDService) LookupTeamPlayers(ctx context.Context, teamID string) ([]thirdparty.TheSportsDBSearchPlayerDto, error) {
+	var result thirdparty.TheSportsDBSearchPlayerResponseDto
+	if err := s.fetch(ctx, "lookup_all_players.php", url.Values{"id": {teamID}}, "failed to look up team players", &result); err != nil {
+		return nil, err
+	}
+	return result.Players, nil
+}
+
+func (s *DefaultTheSportsBDService) LookupPlayer(ctx context.Context, playerID string) (*thirdparty.TheSportsDBSearchPlayerDto, error) {
+	var result thirdparty.TheSportsDBLookupPlayerResponseDto
+	if err := s.fetch(ctx, "lookupplayer.php", url.Values{"id": {playerID}}, "failed to look up player", &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Players) > 0 {
+		return &result.Players[0], nil
+	}
+
+	return nil, notFound("player not found")
+}
+
+// fetch calls an API path and decodes its JSON response into the result, reporting an error status with the message.
+func (s *DefaultTheSportsBDService) fetch(ctx context.Context, path string, query url.Values, failedMessage string, result any) error {
+	resp, err := s.client.get(ctx, s.endpoint(path, query))
 	if err != nil {
-		return nil, exception.NewThirdPartyException("thesportsdb", http.StatusInternalServerError, "failed to call API")
+		return callFailed(err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return rateLimited(resp)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, exception.NewThirdPartyException("thesportsdb", resp.StatusCode, "failed to search team "+teamName+" players")
+		return exception.NewThirdPartyException("thesportsdb", resp.StatusCode, failedMessage)
 	}
 
-	var result thirdparty.TheSportsDBSearchPlayerResponseDto
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, exception.NewThirdPartyException("thesportsdb", http.StatusInternalServerError, "failed to parse response")
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return exception.NewThirdPartyException("thesportsdb", http.StatusInternalServerError, "failed to parse response")
 	}
+	return nil
+}
 
-	return result.Players, nil
+// endpoint returns the URL of an API path, with the query escaped so names like "Brighton & Hove" stay a single parameter.
+func (s *DefaultTheSportsBDService) endpoint(path string, query url.Values) string {
+	return fmt.Sprintf("%s/%s/json/%s/%s?%s", s.apiURL, s.apiVersion, url.PathEscape(s.apiKey), path, query.Encode())
+}
+
+// callFailed reports a call that got no response, telling a timeout apart from an unreachable API.
+// A call refused by the rate limiter is reported as is.
+func callFailed(err error) error {
+	var rateLimitException exception.RateLimitException
+	if errors.As(err, &rateLimitException) {
+		return err
+	}
+	statusCode := http.StatusBadGateway
+	message := "failed to call API"
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		statusCode = http.StatusServiceUnavailable
+		message = "API unavailable, calls suspended"
+	case errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err):
+		statusCode = http.StatusGatewayTimeout
+	}
+	thirdPartyException := exception.NewThirdPartyException("thesportsdb", statusCode, message)
+	thirdPartyException.Cause = err
+	return thirdPartyException
+}
+
+// rateLimited reports a call the API refused because the quota of the API key is exhausted.
+func rateLimited(resp *http.Response) exception.RateLimitException {
+	retryAfter, _ := parseRetryAfter(resp)
+	return exception.RateLimitException{Source: "thesportsdb", RetryAfter: retryAfter}
 }
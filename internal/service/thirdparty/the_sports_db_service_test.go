@@ -0,0 +1,200 @@
+package thirdparty
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"scoreplay/internal/exception"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestTheSportsDBService creates a service calling the handler, with the API key given.
+func newTestTheSportsDBService(t *testing.T, apiKey string, handler http.HandlerFunc) *DefaultTheSportsBDService {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("THESPORTSDB_API_URL", server.URL+"/api")
+	t.Setenv("THESPORTSDB_API_VERSION", "v1")
+	t.Setenv("THESPORTSDB_API_KEY", apiKey)
+	return NewDefaultTheSportsBDService(&http.Client{Timeout: 200 * time.Millisecond}, RetryPolicy{MaxAttempts: 1},
+		NewCircuitBreaker(2, time.Minute), nil)
+}
+
+func TestTheSportsDBServiceEscapesQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKey   string
+		teamName string
+		wantPath string
+	}{
+		{name: "plain name", apiKey: "3", teamName: "Arsenal", wantPath: "/api/v1/json/3/searchteams.php"},
+		{name: "ampersand", apiKey: "3", teamName: "Brighton & Hove Albion", wantPath: "/api/v1/json/3/searchteams.php"},
+		{name: "query characters", apiKey: "3", teamName: "Team?t=Other#1+2", wantPath: "/api/v1/json/3/searchteams.php"},
+		{name: "diacritics", apiKey: "3", teamName: "Atlético Madrid", wantPath: "/api/v1/json/3/searchteams.php"},
+		{name: "key with a slash", apiKey: "a/b", teamName: "Arsenal", wantPath: "/api/v1/json/a%2Fb/searchteams.php"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotPath, gotName string
+			var gotParameters int
+			service := newTestTheSportsDBService(t, test.apiKey, func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotName, gotParameters = r.URL.EscapedPath(), r.URL.Query().Get("t"), len(r.URL.Query())
+				w.Write([]byte(`{"teams": [{"idTeam": "1", "strTeam": "Team"}]}`))
+			})
+
+			if _, err := service.SearchTeam(context.Background(), test.teamName); err != nil {
+				t.Fatalf("SearchTeam: %v", err)
+			}
+			if gotPath != test.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, test.wantPath)
+			}
+			if gotName != test.teamName || gotParameters != 1 {
+				t.Errorf("query t = %q among %d parameters, want %q alone", gotName, gotParameters, test.teamName)
+			}
+		})
+	}
+}
+
+func TestTheSportsDBServicePrefersTheTeamNamedExactly(t *testing.T) {
+	service := newTestTheSportsDBService(t, "3", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"teams": [{"idTeam": "1", "strTeam": "Arsenal Tula"}, {"idTeam": "2", "strTeam": "Arsenal"}]}`))
+	})
+
+	team, err := service.SearchTeam(context.Background(), "arsenal")
+	if err != nil {
+		t.Fatalf("SearchTeam: %v", err)
+	}
+	if team.TeamName != "Arsenal" {
+		t.Errorf("team = %q, want Arsenal", team.TeamName)
+	}
+}
+
+func TestTheSportsDBServiceErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		call       func(service *DefaultTheSportsBDService) error
+		wantStatus int
+	}{
+		{
+			name:    "team not found",
+			handler: respondBody(`{"teams": null}`),
+			call: func(service *DefaultTheSportsBDService) error {
+				_, err := service.SearchTeam(context.Background(), "Nowhere FC")
+				return err
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "player not found",
+			handler: respondBody(`{"players": null}`),
+			call: func(service *DefaultTheSportsBDService) error {
+				_, err := service.LookupPlayer(context.Background(), "1")
+				return err
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "client error",
+			handler: respondStatus(http.StatusUnauthorized),
+			call: func(service *DefaultTheSportsBDService) error {
+				_, err := service.LookupTeam(context.Background(), "1")
+				return err
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "server error",
+			handler: respondStatus(http.StatusInternalServerError),
+			call: func(service *DefaultTheSportsBDService) error {
+				_, err := service.LookupTeamPlayers(context.Background(), "1")
+				return err
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:    "malformed body",
+			handler: respondBody(`{"player": [{"idPlayer": `),
+			call: func(service *DefaultTheSportsBDService) error {
+				_, err := service.SearchPlayers(context.Background(), "Arsenal")
+				return err
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Second)
+			},
+			call: func(service *DefaultTheSportsBDService) error {
+				_, err := service.LookupTeam(context.Background(), "1")
+				return err
+			},
+			wantStatus: http.StatusGatewayTimeout,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestTheSportsDBService(t, "3", test.handler)
+
+			var thirdParty *exception.ThirdPartyException
+			if err := test.call(service); !errors.As(err, &thirdParty) {
+				t.Fatalf("error = %v, want a ThirdPartyException", err)
+			}
+			if thirdParty.StatusCode != test.wantStatus || thirdParty.Source != "thesportsdb" {
+				t.Errorf("error = %v, want status %d from thesportsdb", thirdParty, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTheSportsDBServiceReportsRateLimits(t *testing.T) {
+	service := newTestTheSportsDBService(t, "3", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := service.LookupTeam(context.Background(), "1")
+	var rateLimit exception.RateLimitException
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("error = %v, want a RateLimitException", err)
+	}
+	if rateLimit.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %s, want 7s", rateLimit.RetryAfter)
+	}
+}
+
+func TestTheSportsDBServiceSuspendsCallsWhileTheBreakerIsOpen(t *testing.T) {
+	var calls atomic.Int32
+	service := newTestTheSportsDBService(t, "3", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	for i := 0; i < 3; i++ {
+		service.LookupTeam(context.Background(), "1")
+	}
+	_, err := service.LookupTeam(context.Background(), "1")
+	var thirdParty *exception.ThirdPartyException
+	if !errors.As(err, &thirdParty) || thirdParty.StatusCode != http.StatusServiceUnavailable || !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want a 503 ThirdPartyException caused by the open breaker", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2 before the breaker opened", calls.Load())
+	}
+}
+
+func respondBody(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}
+}
+
+func respondStatus(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}
+}
@@ -1,14 +1,20 @@
 package thirdparty
 
 import (
+	"context"
+	"scoreplay/internal/dto"
 	"scoreplay/internal/dto/thirdparty"
 )
 
 type TheSportsDBService interface {
-	SearchTeam(teamName string) (*thirdparty.TheSportsDBSearchTeamDto, error)
-	SearchPlayers(teamName string) ([]thirdparty.TheSportsDBSearchPlayerDto, error)
+	SearchTeam(ctx context.Context, teamName string) (*thirdparty.TheSportsDBSearchTeamDto, error)
+	SearchPlayers(ctx context.Context, teamName string) ([]thirdparty.TheSportsDBSearchPlayerDto, error)
+	LookupTeam(ctx context.Context, teamID string) (*thirdparty.TheSportsDBSearchTeamDto, error)
+	LookupTeamPlayers(ctx context.Context, teamID string) ([]thirdparty.TheSportsDBSearchPlayerDto, error)
+	LookupPlayer(ctx context.Context, playerID string) (*thirdparty.TheSportsDBSearchPlayerDto, error)
 }
 
-type ThirdPartyService struct {
-	theSportsDBService TheSportsDBService
+// CacheMonitor exposes the statistics of a cache of third party lookups.
+type CacheMonitor interface {
+	Stats() dto.CacheStatsDTO
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"log"
+	"scoreplay/internal/dto"
+	"scoreplay/internal/mapper"
+	"scoreplay/internal/repository"
+	"sort"
+	"time"
+)
+
+// DefaultTrashService lists the soft-deleted teams and players, and purges them once the retention has elapsed.
+type DefaultTrashService struct {
+	repository   repository.Repository
+	teamMapper   mapper.TeamMapper
+	playerMapper mapper.PlayerMapper
+	retention    time.Duration
+}
+
+func NewDefaultTrashService(repository repository.Repository, teamMapper mapper.TeamMapper, playerMapper mapper.PlayerMapper,
+	retention time.Duration) *DefaultTrashService {
+	return &DefaultTrashService{
+		repository:   repository,
+		teamMapper:   teamMapper,
+		playerMapper: playerMapper,
+		retention:    retention,
+	}
+}
+
+// GetTrash lists the deleted teams and players, most recently deleted first.
+func (s *DefaultTrashService) GetTrash(ctx context.Context) (*dto.TrashDTO, error) {
+	teams, err := s.repository.Team.GetDeleted()
+	if err != nil {
+		return nil, err
+	}
+	players, err := s.repository.Player.GetDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &dto.TrashDTO{
+		Teams:   []dto.TeamDTO{},
+		Players: []dto.PlayerDTO{},
+	}
+	trash.Teams = append(trash.Teams, s.teamMapper.MapToTeamDTOList(teams)...)
+	trash.Players = append(trash.Players, s.playerMapper.MapToPlayerDTOList(players)...)
+	sort.Slice(trash.Teams, func(i, j int) bool {
+		return trash.Teams[i].DeletedAt.After(*trash.Teams[j].DeletedAt)
+	})
+	sort.Slice(trash.Players, func(i, j int) bool {
+		return trash.Players[i].DeletedAt.After(*trash.Players[j].DeletedAt)
+	})
+	return trash, nil
+}
+
+// PurgeTrash permanently removes the teams and players deleted for longer than the retention.
+func (s *DefaultTrashService) PurgeTrash(ctx context.Context) (*dto.PurgeReportDTO, error) {
+	deletedBefore := time.Now().UTC().Add(-s.retention)
+	report := &dto.PurgeReportDTO{}
+	err := s.repository.InTransaction(func(tx repository.Transaction) error {
+		tx = auditTransaction(ctx, tx)
+		teamIDs, err := tx.Team().Purge(deletedBefore)
+		if err != nil {
+			return err
+		}
+		playerIDs, err := tx.Player().Purge(deletedBefore)
+		if err != nil {
+			return err
+		}
+		report.TeamsPurged = len(teamIDs)
+		report.PlayersPurged = len(playerIDs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// PurgePeriodically purges the trash every interval until the context is done, a non-positive interval disables it.
+func (s *DefaultTrashService) PurgePeriodically(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			report, err := s.PurgeTrash(ctx)
+			if err != nil {
+				log.Printf("Error purging trash: %v", err)
+				continue
+			}
+			if report.TeamsPurged > 0 || report.PlayersPurged > 0 {
+				log.Printf("Purged %d teams and %d players from the trash", report.TeamsPurged, report.PlayersPurged)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}